@@ -0,0 +1,271 @@
+package api
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"io.librablock.go/controllers"
+	"io.librablock.go/models"
+	"io.librablock.go/proto/types"
+	"io.librablock.go/utils"
+	"io.librablock.go/verifier"
+)
+
+// NewRouter serves the stored chain from db and proxies account queries to
+// the node through rpc.
+func NewRouter(db utils.Store, rpc *controllers.LibraRPC) *gin.Engine {
+	r := gin.Default()
+
+	r.GET("/version", func(c *gin.Context) {
+		offsetStr := c.DefaultQuery("offset", "0")
+		limitStr := c.DefaultQuery("limit", "20")
+		address := c.Query("address")
+
+		offset, err1 := strconv.Atoi(offsetStr)
+		limit, err2 := strconv.Atoi(limitStr)
+
+		if err1 != nil || err2 != nil {
+			c.JSON(400, gin.H{"message": "bad request"})
+			return
+		}
+
+		var versions []models.BlockModel
+		var err error
+		if address == "" {
+			versions, err = db.GetVersions(offset, limit)
+		} else {
+			versions, err = db.GetVersionsRefAddress(address, offset, limit)
+		}
+
+		if err != nil {
+			c.JSON(500, gin.H{"message": "internal server error"})
+		} else {
+			c.JSON(200, versions)
+		}
+
+	})
+
+	r.GET("/version/:id", func(c *gin.Context) {
+		id := c.Param("id")
+		id64, err := strconv.ParseInt(id, 10, 64)
+
+		if err != nil {
+			c.JSON(400, gin.H{"message": "bad request"})
+			return
+		}
+
+		version, err := db.GetVersion(uint64(id64))
+		if err == utils.ErrNotFound {
+			c.JSON(404, gin.H{"message": "not found"})
+		} else if err != nil {
+			c.JSON(500, gin.H{"message": "internal server error"})
+		} else {
+			c.JSON(200, version)
+		}
+	})
+
+	r.GET("/version/:id/events", func(c *gin.Context) {
+		id := c.Param("id")
+		id64, err := strconv.ParseInt(id, 10, 64)
+
+		if err != nil {
+			c.JSON(400, gin.H{"message": "bad request"})
+			return
+		}
+
+		events, err := db.GetEventsByVersion(uint64(id64))
+		if err != nil {
+			c.JSON(500, gin.H{"message": "internal server error"})
+		} else {
+			c.JSON(200, events)
+		}
+	})
+
+	r.GET("/events/:key", func(c *gin.Context) {
+		key := c.Param("key")
+		offsetStr := c.DefaultQuery("offset", "0")
+		limitStr := c.DefaultQuery("limit", "20")
+
+		offset, err1 := strconv.Atoi(offsetStr)
+		limit, err2 := strconv.Atoi(limitStr)
+		_, err3 := controllers.HexToBytes(key)
+
+		if err1 != nil || err2 != nil || err3 != nil {
+			c.JSON(400, gin.H{"message": "bad request"})
+			return
+		}
+
+		events, err := db.GetEventsByKey(key, offset, limit)
+		if err != nil {
+			c.JSON(500, gin.H{"message": "internal server error"})
+		} else {
+			c.JSON(200, events)
+		}
+	})
+
+	r.GET("/account/:address", func(c *gin.Context) {
+		address := c.Param("address")
+		_, err1 := controllers.HexToBytes(address)
+
+		var version uint64
+		var err2 error
+		versionStr := c.Query("version")
+		if versionStr != "" {
+			version, err2 = strconv.ParseUint(versionStr, 10, 64)
+		}
+
+		if len(address) != 64 || err1 != nil || err2 != nil {
+			c.JSON(400, gin.H{"message": "bad request"})
+			return
+		}
+
+		var r *models.AccountModel
+		var err error
+		if versionStr != "" {
+			r, err = rpc.GetAccountStateByVersion(c.Request.Context(), address, version)
+		} else {
+			r, err = rpc.GetAccountState(c.Request.Context(), address)
+		}
+
+		if err == controllers.ErrNoStorage {
+			c.JSON(501, gin.H{"message": "historical account state is not available"})
+		} else if verifier.IsVerificationError(err) {
+			c.JSON(502, gin.H{"message": "account state proof verification failed"})
+		} else if err != nil {
+			c.JSON(400, gin.H{"message": "bad request"})
+		} else {
+			if r == nil {
+				c.JSON(404, gin.H{"message": "not found"})
+			} else {
+				c.JSON(200, r)
+			}
+		}
+	})
+
+	r.GET("/account/:address/sequence/:seq", func(c *gin.Context) {
+		address := c.Param("address")
+		_, err1 := controllers.HexToBytes(address)
+		seq, err2 := strconv.ParseUint(c.Param("seq"), 10, 64)
+
+		if len(address) != 64 || err1 != nil || err2 != nil {
+			c.JSON(400, gin.H{"message": "bad request"})
+			return
+		}
+
+		r, err := rpc.GetAccountTransactionBySequenceNumber(c.Request.Context(), address, seq, true)
+
+		if verifier.IsVerificationError(err) {
+			c.JSON(502, gin.H{"message": "transaction proof verification failed"})
+		} else if err != nil {
+			c.JSON(400, gin.H{"message": "bad request"})
+		} else {
+			c.JSON(200, r)
+		}
+	})
+
+	r.GET("/account/:address/overview", func(c *gin.Context) {
+		address := c.Param("address")
+		_, err1 := controllers.HexToBytes(address)
+		limit, err2 := strconv.ParseUint(c.DefaultQuery("events", "10"), 10, 64)
+
+		if len(address) != 64 || err1 != nil || err2 != nil {
+			c.JSON(400, gin.H{"message": "bad request"})
+			return
+		}
+
+		q := rpc.NewQuery()
+		account := q.AccountState(address)
+		sent := q.AccountEvents(address, controllers.SentPaymentEventType, controllers.LatestEventSequenceNumber, false, limit)
+		received := q.AccountEvents(address, controllers.ReceivedPaymentEventType, controllers.LatestEventSequenceNumber, false, limit)
+		err := q.Execute(c.Request.Context())
+
+		if verifier.IsVerificationError(err) {
+			c.JSON(502, gin.H{"message": "proof verification failed"})
+		} else if err != nil {
+			c.JSON(400, gin.H{"message": "bad request"})
+		} else if account.Account == nil {
+			c.JSON(404, gin.H{"message": "not found"})
+		} else {
+			c.JSON(200, gin.H{
+				"account":        account.Account,
+				"sent":           sent.Events.Events,
+				"received":       received.Events.Events,
+				"ledger_version": q.LedgerVersion,
+			})
+		}
+	})
+
+	accountEvents := func(eventType string) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			address := c.Param("address")
+			_, err1 := controllers.HexToBytes(address)
+			ascending, err2 := strconv.ParseBool(c.DefaultQuery("ascending", "true"))
+			limit, err3 := strconv.ParseUint(c.DefaultQuery("limit", "20"), 10, 64)
+
+			start := uint64(0)
+			if !ascending {
+				start = controllers.LatestEventSequenceNumber
+			}
+			var err4 error
+			if startStr := c.Query("start"); startStr != "" {
+				start, err4 = strconv.ParseUint(startStr, 10, 64)
+			}
+
+			if len(address) != 64 || err1 != nil || err2 != nil || err3 != nil || err4 != nil {
+				c.JSON(400, gin.H{"message": "bad request"})
+				return
+			}
+
+			r, err := rpc.GetAccountEvents(c.Request.Context(), address, eventType, start, ascending, limit)
+
+			if verifier.IsVerificationError(err) {
+				c.JSON(502, gin.H{"message": "event proof verification failed"})
+			} else if err != nil {
+				c.JSON(400, gin.H{"message": "bad request"})
+			} else {
+				c.JSON(200, r)
+			}
+		}
+	}
+
+	r.GET("/account/:address/sent", accountEvents(controllers.SentPaymentEventType))
+	r.GET("/account/:address/received", accountEvents(controllers.ReceivedPaymentEventType))
+
+	r.POST("/transactions", func(c *gin.Context) {
+		var body struct {
+			RawTxnBytes     string `json:"raw_txn_bytes" binding:"required"`
+			SenderPublicKey string `json:"sender_public_key" binding:"required"`
+			SenderSignature string `json:"sender_signature" binding:"required"`
+		}
+
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(400, gin.H{"message": "bad request"})
+			return
+		}
+
+		rawTxnBytes, err1 := controllers.HexToBytes(body.RawTxnBytes)
+		publicKey, err2 := controllers.HexToBytes(body.SenderPublicKey)
+		signature, err3 := controllers.HexToBytes(body.SenderSignature)
+
+		if err1 != nil || err2 != nil || err3 != nil {
+			c.JSON(400, gin.H{"message": "bad request"})
+			return
+		}
+
+		r, err := rpc.SubmitTransaction(c.Request.Context(), &types.SignedTransaction{
+			RawTxnBytes:     rawTxnBytes,
+			SenderPublicKey: publicKey,
+			SenderSignature: signature,
+		})
+
+		if err != nil {
+			c.JSON(502, gin.H{"message": "failed to submit transaction"})
+		} else if !r.Accepted {
+			c.JSON(400, r)
+		} else {
+			c.JSON(200, r)
+		}
+	})
+
+	return r
+}
@@ -0,0 +1,198 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"io.librablock.go/controllers"
+	"io.librablock.go/lcs"
+	"io.librablock.go/testutil"
+	"io.librablock.go/testutil/fakerpc"
+	"io.librablock.go/utils"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// fakeRouter serves a fake node with payments from alice to bob, and a
+// router on top of it whose store holds versions 1 to payments-1.
+func fakeRouter(t *testing.T, payments int) (*testutil.FakeAdmissionControl, *controllers.LibraRPC, *gin.Engine, func()) {
+	ac := testutil.PaymentLedger(t, payments)
+	ac.Validators = testutil.FakeValidators(t, 4)
+	rpc, stop := fakerpc.Dial(t, ac)
+
+	db := utils.NewMemoryStore()
+	blocks, err := rpc.GetTransactions(context.Background(), 1, uint64(payments-1), true)
+	if err != nil {
+		stop()
+		t.Fatal(err)
+	}
+	if err := db.SaveBlocks(*blocks); err != nil {
+		stop()
+		t.Fatal(err)
+	}
+
+	return ac, rpc, NewRouter(db, rpc), stop
+}
+
+func get(t *testing.T, r *gin.Engine, path string, result interface{}) int {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+
+	if result != nil && w.Code == 200 {
+		if err := json.Unmarshal(w.Body.Bytes(), result); err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+	}
+	return w.Code
+}
+
+func TestRouterStore(t *testing.T) {
+	_, _, r, stop := fakeRouter(t, 5)
+	defer stop()
+
+	var versions []map[string]interface{}
+	if code := get(t, r, "/version?limit=2", &versions); code != 200 || len(versions) != 2 || versions[0]["version"] != 4.0 {
+		t.Errorf("/version: %d %v", code, versions)
+	}
+
+	if code := get(t, r, "/version?address="+controllers.BytesToHex(testutil.Bob), &versions); code != 200 || len(versions) != 4 {
+		t.Errorf("/version?address: %d %v", code, versions)
+	}
+
+	var version map[string]interface{}
+	if code := get(t, r, "/version/2", &version); code != 200 || version["source"] != controllers.BytesToHex(testutil.Alice) {
+		t.Errorf("/version/2: %d %v", code, version)
+	}
+
+	var events []map[string]interface{}
+	if code := get(t, r, "/version/2/events", &events); code != 200 || len(events) != 2 {
+		t.Errorf("/version/2/events: %d %v", code, events)
+	}
+
+	key := controllers.BytesToHex(lcs.AccountEventKey(testutil.Alice, lcs.SentEventsPath))
+	if code := get(t, r, "/events/"+key, &events); code != 200 || len(events) != 4 || events[0]["sequence_number"] != 4.0 {
+		t.Errorf("/events/:key: %d %v", code, events)
+	}
+
+	statuses := map[string]int{
+		"/version/99":        404,
+		"/version/x":         400,
+		"/version?limit=x":   400,
+		"/events/zz":         400,
+		"/version/99/events": 200,
+	}
+	for path, want := range statuses {
+		if code := get(t, r, path, nil); code != want {
+			t.Errorf("GET %s: got %d, want %d", path, code, want)
+		}
+	}
+}
+
+func TestRouterAccount(t *testing.T) {
+	_, _, r, stop := fakeRouter(t, 5)
+	defer stop()
+
+	var account map[string]interface{}
+	if code := get(t, r, "/account/"+controllers.BytesToHex(testutil.Alice), &account); code != 200 || account["sequence_number"] != 5.0 || account["verified"] != true {
+		t.Errorf("/account: %d %v", code, account)
+	}
+
+	var txn map[string]interface{}
+	if code := get(t, r, "/account/"+controllers.BytesToHex(testutil.Alice)+"/sequence/2", &txn); code != 200 || txn["committed"] != true || txn["account_sequence_number"] != 5.0 {
+		t.Errorf("/account/:address/sequence: %d %v", code, txn)
+	}
+	if events, _ := txn["events"].([]interface{}); len(events) != 2 {
+		t.Errorf("/account/:address/sequence events: %v", txn["events"])
+	}
+
+	var received map[string]interface{}
+	if code := get(t, r, "/account/"+controllers.BytesToHex(testutil.Bob)+"/received?limit=2", &received); code != 200 || received["event_count"] != 5.0 {
+		t.Errorf("/account/:address/received: %d %v", code, received)
+	}
+	if events, _ := received["events"].([]interface{}); len(events) != 2 {
+		t.Errorf("/account/:address/received events: %v", received["events"])
+	}
+
+	var overview map[string]interface{}
+	if code := get(t, r, "/account/"+controllers.BytesToHex(testutil.Alice)+"/overview?events=3", &overview); code != 200 || overview["ledger_version"] != 4.0 {
+		t.Errorf("/account/:address/overview: %d %v", code, overview)
+	}
+	if sent, _ := overview["sent"].([]interface{}); len(sent) != 3 {
+		t.Errorf("/account/:address/overview sent: %v", overview["sent"])
+	}
+
+	statuses := map[string]int{
+		"/account/" + controllers.BytesToHex(make([]byte, 32)): 404,
+		"/account/aa": 400,
+		"/account/" + controllers.BytesToHex(testutil.Alice) + "?version=1":          501,
+		"/account/" + controllers.BytesToHex(testutil.Alice) + "/sequence/x":         400,
+		"/account/" + controllers.BytesToHex(testutil.Alice) + "/sent?ascending=x":   400,
+		"/account/" + controllers.BytesToHex(testutil.Alice) + "/overview?events=-1": 400,
+	}
+	for path, want := range statuses {
+		if code := get(t, r, path, nil); code != want {
+			t.Errorf("GET %s: got %d, want %d", path, code, want)
+		}
+	}
+}
+
+func TestRouterVerificationFailure(t *testing.T) {
+	ac, _, r, stop := fakeRouter(t, 3)
+	defer stop()
+
+	// from now on the node signs with validators nobody trusts
+	ac.Validators = ac.Validators[:1]
+
+	paths := []string{
+		"/account/" + controllers.BytesToHex(testutil.Alice),
+		"/account/" + controllers.BytesToHex(testutil.Alice) + "/sequence/1",
+		"/account/" + controllers.BytesToHex(testutil.Alice) + "/sent",
+		"/account/" + controllers.BytesToHex(testutil.Alice) + "/overview",
+	}
+	for _, path := range paths {
+		if code := get(t, r, path, nil); code != 502 {
+			t.Errorf("GET %s: got %d, want 502", path, code)
+		}
+	}
+}
+
+func TestRouterSubmitTransaction(t *testing.T) {
+	ac, _, r, stop := fakeRouter(t, 2)
+	defer stop()
+
+	txn, err := testutil.PaymentTransaction(testutil.Alice, 2, testutil.Bob, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := json.Marshal(map[string]string{
+		"raw_txn_bytes":     controllers.BytesToHex(txn.RawTxnBytes),
+		"sender_public_key": controllers.BytesToHex(txn.SenderPublicKey),
+		"sender_signature":  controllers.BytesToHex(txn.SenderSignature),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/transactions", bytes.NewReader(body)))
+	if w.Code != 200 {
+		t.Fatalf("POST /transactions: %d %s", w.Code, w.Body.String())
+	}
+
+	submitted := ac.Submitted()
+	if len(submitted) != 1 || !bytes.Equal(submitted[0].RawTxnBytes, txn.RawTxnBytes) {
+		t.Errorf("node got %v", submitted)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/transactions", bytes.NewReader([]byte(`{"raw_txn_bytes": "zz"}`))))
+	if w.Code != 400 {
+		t.Errorf("POST /transactions with a bad body: %d", w.Code)
+	}
+}
@@ -1,78 +1,95 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/url"
 	"os"
-	"time"
+	"os/signal"
+	"strconv"
+	"syscall"
 
 	"io.librablock.go/controllers"
+	"io.librablock.go/fetcher"
 	"io.librablock.go/utils"
+	"io.librablock.go/verifier"
 )
 
-func haveARest() {
-	time.Sleep(250 * time.Microsecond)
+func backfillOptions() fetcher.BackfillOptions {
+	opts := fetcher.BackfillOptions{Window: fetcher.DefaultWindow}
+
+	if v, err := strconv.ParseUint(os.Getenv("LIBRA_FETCHER_WINDOW"), 10, 64); err == nil {
+		opts.Window = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("LIBRA_FETCHER_PARALLELISM")); err == nil {
+		opts.Parallelism = v
+	}
+
+	return opts
 }
 
 func main() {
 	botKey := os.Getenv("LIBRA_BOT_KEY")
 	botSecret := os.Getenv("LIBRA_BOT_SECRET")
 	chatId := os.Getenv("LIBRA_BOT_CHAT_ID")
-	dbURL := os.Getenv("LIBRA_MYSQL_URL")
+	dbURL := utils.DatabaseURLFromEnv()
 
 	telegramURL := fmt.Sprintf("https://api.telegram.org/%s:%s/sendMessage?chat_id=%s&parse_mode=markdown&text=", botKey, botSecret, chatId)
 	fmt.Println(telegramURL)
 
-	rpc := controllers.NewLibraRPC(nil)
-	db := utils.NewDataBaseAdapter(dbURL)
-
-	db.Migration()
-
-	errCnt := 0
+	rpc, err := controllers.NewLibraRPC(controllers.AddressFromEnv(), controllers.RPCOptionsFromEnv())
+	if err != nil {
+		fmt.Printf("Failed to set up rpc: %s\n", err.Error())
+		return
+	}
+	defer rpc.Close()
 
-	for {
-		if errCnt > 10 {
-			fmt.Printf("Max Retry Times")
+	rpc.VerifyProofs = os.Getenv("LIBRA_FETCHER_VERIFY") == "true"
 
-			_, _ = http.Get(telegramURL + url.QueryEscape("libra block fetcher failed 10 times"))
-			break
+	if path := os.Getenv("LIBRA_TRUSTED_STATE"); path != "" {
+		state, err := verifier.LoadTrustedState(path)
+		if err != nil {
+			fmt.Printf("Failed to load trusted state: %s\n", err.Error())
+			return
 		}
+		rpc.TrustedState = state
 
-		latestVersion, err := rpc.GetLatestVersion()
-		if err != nil {
-			errCnt += 1
+		defer func() {
+			if err := state.Save(); err != nil {
+				fmt.Printf("Failed to save trusted state: %s\n", err.Error())
+			}
+		}()
+	}
 
-			haveARest()
-			continue
-		}
+	db, err := utils.OpenStore(dbURL, utils.DBOptionsFromEnv())
+	if err != nil {
+		fmt.Printf("Failed to connect database: %s\n", err.Error())
+		return
+	}
+	defer db.Close()
 
-		dbLatestVersion := db.GetLatestVersion()
+	if err := db.Migration(); err != nil {
+		fmt.Printf("Failed to migrate database: %s\n", err.Error())
+		return
+	}
 
-		limit := latestVersion - dbLatestVersion
+	opts := backfillOptions()
 
-		if limit == 0 {
-			haveARest()
-			errCnt = 0
-			continue
-		}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
-		if limit > 1000 {
-			limit = 1000
-		}
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		fmt.Println("Shutting down")
+		cancel()
+	}()
 
-		r, err := rpc.GetTransactions(dbLatestVersion+1, limit, false)
-		if err != nil {
-			errCnt += 1
-			haveARest()
-			continue
-		}
+	if err := fetcher.Run(ctx, rpc, db, opts); err == fetcher.ErrMaxRetry {
+		fmt.Printf("Max Retry Times")
 
-		for _, v := range *r {
-			db.SaveBlock(v)
-			fmt.Printf("Success Fetch Version: %d\n", v.Version)
-		}
-		errCnt = 0
+		_, _ = http.Get(telegramURL + url.QueryEscape("libra block fetcher failed 10 times"))
 	}
-
 }
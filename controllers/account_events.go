@@ -0,0 +1,152 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"io.librablock.go/lcs"
+	"io.librablock.go/models"
+	"io.librablock.go/proto/types"
+	"io.librablock.go/verifier"
+)
+
+// LatestEventSequenceNumber asks the node for the newest events of a handle.
+const LatestEventSequenceNumber = ^uint64(0)
+
+// GetAccountEvents walks the sent or received payment event handle of an
+// account, starting at event sequence number start. eventType is either
+// SentPaymentEventType or ReceivedPaymentEventType.
+func (libra *LibraRPC) GetAccountEvents(ctx context.Context, address string, eventType string, start uint64, ascending bool, limit uint64) (*models.AccountEventsModel, error) {
+	addressBytes, err := HexToBytes(address)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit > 50 {
+		limit = 50
+	}
+
+	path, err := accountEventPath(eventType)
+	if err != nil {
+		return nil, err
+	}
+
+	// the account state proves the handle and how many events it holds,
+	// which the node only sends along for some pages
+	r, err := libra.updateToLatestLedgerRequest(ctx, []*types.RequestItem{
+		libra.getAccountStateRequestMaker(addressBytes),
+		libra.getEventsByEventAccessPathRequestMaker(addressBytes, path, start, ascending, limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var state *types.AccountStateWithProof
+	for _, v := range r.ResponseItems {
+		switch val := v.ResponseItems.(type) {
+		case *types.ResponseItem_GetAccountStateResponse:
+			state = val.GetAccountStateResponse.AccountStateWithProof
+		case *types.ResponseItem_GetEventsByEventAccessPathResponse:
+			return libra.newAccountEventsModel(addressBytes, eventType, start, ascending, limit, r.LedgerInfoWithSigs.GetLedgerInfo(), state, val.GetEventsByEventAccessPathResponse)
+		}
+	}
+
+	return nil, errors.New("missing events in response")
+}
+
+func accountEventPath(eventType string) ([]byte, error) {
+	switch eventType {
+	case SentPaymentEventType:
+		return lcs.AccountEventPath(lcs.SentEventsPath), nil
+	case ReceivedPaymentEventType:
+		return lcs.AccountEventPath(lcs.ReceivedEventsPath), nil
+	default:
+		return nil, fmt.Errorf("unknown account event type %q", eventType)
+	}
+}
+
+// expectedEventSequenceNumbers is the page of sequence numbers a handle
+// holding count events must answer for start, ascending and limit.
+func expectedEventSequenceNumbers(count uint64, start uint64, ascending bool, limit uint64) []uint64 {
+	var result []uint64
+	if count == 0 {
+		return result
+	}
+
+	if ascending {
+		for seq := start; seq < count && uint64(len(result)) < limit; seq++ {
+			result = append(result, seq)
+		}
+		return result
+	}
+
+	if start > count-1 {
+		start = count - 1
+	}
+	for seq := start; uint64(len(result)) < limit; seq-- {
+		result = append(result, seq)
+		if seq == 0 {
+			break
+		}
+	}
+	return result
+}
+
+// newAccountEventsModel verifies a page of events against ledgerInfo and
+// checks it is exactly the page asked for: events of the handle read from
+// the proven account state, in order and without gaps.
+func (libra *LibraRPC) newAccountEventsModel(address []byte, eventType string, start uint64, ascending bool, limit uint64, ledgerInfo *types.LedgerInfo, state *types.AccountStateWithProof, resp *types.GetEventsByEventAccessPathResponse) (*models.AccountEventsModel, error) {
+	if ledgerInfo == nil {
+		return nil, errors.New("missing ledger info in response")
+	}
+
+	account, err := libra.newAccountModel(address, ledgerInfo, state)
+	if err != nil {
+		return nil, err
+	}
+
+	var count uint64
+	var key []byte
+	if account != nil {
+		if eventType == SentPaymentEventType {
+			count = account.SentEventCount
+			key = lcs.AccountEventKey(address, lcs.SentEventsPath)
+		} else {
+			count = account.ReceivedEventCount
+			key = lcs.AccountEventKey(address, lcs.ReceivedEventsPath)
+		}
+	}
+
+	expected := expectedEventSequenceNumbers(count, start, ascending, limit)
+	if len(resp.EventsWithProof) != len(expected) {
+		return nil, verifier.Errorf("got %d events, want %d", len(resp.EventsWithProof), len(expected))
+	}
+
+	result := models.AccountEventsModel{}
+	result.Address = BytesToHex(address)
+	result.Type = eventType
+	result.Events = []models.EventModel{}
+	result.EventCount = &count
+	result.Verified = libra.TrustedState != nil
+	result.LedgerVersion = ledgerInfo.Version
+
+	for i, e := range resp.EventsWithProof {
+		if err := verifier.VerifyEventWithProof(ledgerInfo, e); err != nil {
+			return nil, err
+		}
+
+		if !bytes.Equal(e.Event.GetKey(), key) {
+			return nil, verifier.Errorf("event %d has key %x, want %x", i, e.Event.GetKey(), key)
+		}
+		if e.Event.SequenceNumber != expected[i] {
+			return nil, verifier.Errorf("event %d has sequence number %d, want %d", i, e.Event.SequenceNumber, expected[i])
+		}
+
+		event := newEventModel(e.TransactionVersion, e.EventIndex, e.Event)
+		result.Events = append(result.Events, event)
+	}
+
+	return &result, nil
+}
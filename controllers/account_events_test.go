@@ -0,0 +1,168 @@
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"io.librablock.go/lcs"
+	"io.librablock.go/proto/types"
+	"io.librablock.go/testutil"
+	"io.librablock.go/verifier"
+)
+
+func getAccountEvents(t *testing.T, ac *testutil.FakeAdmissionControl, address []byte, path string, start uint64, ascending bool, limit uint64) (*types.LedgerInfo, *types.AccountStateWithProof, *types.GetEventsByEventAccessPathResponse) {
+	libra := LibraRPC{}
+	resp, err := ac.UpdateToLatestLedger(context.Background(), &types.UpdateToLatestLedgerRequest{
+		RequestedItems: []*types.RequestItem{
+			libra.getAccountStateRequestMaker(address),
+			libra.getEventsByEventAccessPathRequestMaker(address, lcs.AccountEventPath(path), start, ascending, limit),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp = proto.Clone(resp).(*types.UpdateToLatestLedgerResponse)
+	return resp.LedgerInfoWithSigs.LedgerInfo,
+		resp.ResponseItems[0].GetGetAccountStateResponse().AccountStateWithProof,
+		resp.ResponseItems[1].GetGetEventsByEventAccessPathResponse()
+}
+
+func eventSequenceNumbers(events []*types.EventWithProof) []uint64 {
+	var result []uint64
+	for _, e := range events {
+		result = append(result, e.Event.SequenceNumber)
+	}
+	return result
+}
+
+func TestExpectedEventSequenceNumbers(t *testing.T) {
+	tests := []struct {
+		count     uint64
+		start     uint64
+		ascending bool
+		limit     uint64
+		want      []uint64
+	}{
+		{5, 0, true, 3, []uint64{0, 1, 2}},
+		{5, 3, true, 3, []uint64{3, 4}},
+		{5, 5, true, 3, nil},
+		{0, 0, true, 3, nil},
+		{5, LatestEventSequenceNumber, false, 3, []uint64{4, 3, 2}},
+		{5, 1, false, 3, []uint64{1, 0}},
+		{5, 9, false, 2, []uint64{4, 3}},
+		{0, LatestEventSequenceNumber, false, 3, nil},
+		{5, 0, true, 0, nil},
+	}
+
+	for _, tt := range tests {
+		got := expectedEventSequenceNumbers(tt.count, tt.start, tt.ascending, tt.limit)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("expectedEventSequenceNumbers(%d, %d, %v, %d) = %v, want %v", tt.count, tt.start, tt.ascending, tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestNewAccountEventsModel(t *testing.T) {
+	ac := testutil.PaymentLedger(t, 5)
+	libra := LibraRPC{}
+
+	tests := []struct {
+		start     uint64
+		ascending bool
+		limit     uint64
+		want      []uint64
+	}{
+		{1, true, 2, []uint64{1, 2}},
+		{3, true, 10, []uint64{3, 4}},
+		{LatestEventSequenceNumber, false, 3, []uint64{4, 3, 2}},
+		{1, false, 10, []uint64{1, 0}},
+	}
+
+	for _, tt := range tests {
+		ledgerInfo, state, resp := getAccountEvents(t, ac, testutil.Alice, lcs.SentEventsPath, tt.start, tt.ascending, tt.limit)
+		result, err := libra.newAccountEventsModel(testutil.Alice, SentPaymentEventType, tt.start, tt.ascending, tt.limit, ledgerInfo, state, resp)
+		if err != nil {
+			t.Fatalf("start %d: %v", tt.start, err)
+		}
+
+		var got []uint64
+		for _, e := range result.Events {
+			got = append(got, e.SequenceNumber)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("start %d: got %v, want %v", tt.start, got, tt.want)
+		}
+
+		// the count comes from the account state whether or not the node
+		// proved the latest event
+		if result.EventCount == nil || *result.EventCount != 5 {
+			t.Errorf("start %d: got event count %v", tt.start, result.EventCount)
+		}
+	}
+}
+
+func TestNewAccountEventsModelUnknownAccount(t *testing.T) {
+	ac := testutil.PaymentLedger(t, 2)
+	libra := LibraRPC{}
+	carol := make([]byte, 32)
+
+	ledgerInfo, state, resp := getAccountEvents(t, ac, carol, lcs.SentEventsPath, 0, true, 10)
+	result, err := libra.newAccountEventsModel(carol, SentPaymentEventType, 0, true, 10, ledgerInfo, state, resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result.Events) != 0 || *result.EventCount != 0 {
+		t.Errorf("got %+v", result)
+	}
+}
+
+func TestNewAccountEventsModelRejectsWrongPage(t *testing.T) {
+	ac := testutil.PaymentLedger(t, 5)
+	libra := LibraRPC{}
+
+	tests := []struct {
+		name   string
+		tamper func(resp *types.GetEventsByEventAccessPathResponse) *types.GetEventsByEventAccessPathResponse
+	}{
+		{"other handle", func(resp *types.GetEventsByEventAccessPathResponse) *types.GetEventsByEventAccessPathResponse {
+			// proven events of the same sequence numbers, but bob's
+			_, _, received := getAccountEvents(t, ac, testutil.Bob, lcs.ReceivedEventsPath, 1, true, 3)
+			return received
+		}},
+		{"wrong start", func(resp *types.GetEventsByEventAccessPathResponse) *types.GetEventsByEventAccessPathResponse {
+			_, _, later := getAccountEvents(t, ac, testutil.Alice, lcs.SentEventsPath, 2, true, 3)
+			return later
+		}},
+		{"gap", func(resp *types.GetEventsByEventAccessPathResponse) *types.GetEventsByEventAccessPathResponse {
+			_, _, more := getAccountEvents(t, ac, testutil.Alice, lcs.SentEventsPath, 1, true, 4)
+			more.EventsWithProof = append(more.EventsWithProof[:1], more.EventsWithProof[2:]...)
+			return more
+		}},
+		{"reordered", func(resp *types.GetEventsByEventAccessPathResponse) *types.GetEventsByEventAccessPathResponse {
+			resp.EventsWithProof[0], resp.EventsWithProof[1] = resp.EventsWithProof[1], resp.EventsWithProof[0]
+			return resp
+		}},
+		{"truncated", func(resp *types.GetEventsByEventAccessPathResponse) *types.GetEventsByEventAccessPathResponse {
+			resp.EventsWithProof = resp.EventsWithProof[:2]
+			return resp
+		}},
+		{"descending", func(resp *types.GetEventsByEventAccessPathResponse) *types.GetEventsByEventAccessPathResponse {
+			_, _, descending := getAccountEvents(t, ac, testutil.Alice, lcs.SentEventsPath, 3, false, 3)
+			return descending
+		}},
+	}
+
+	for _, tt := range tests {
+		ledgerInfo, state, resp := getAccountEvents(t, ac, testutil.Alice, lcs.SentEventsPath, 1, true, 3)
+		resp = tt.tamper(resp)
+
+		_, err := libra.newAccountEventsModel(testutil.Alice, SentPaymentEventType, 1, true, 3, ledgerInfo, state, resp)
+		if !verifier.IsVerificationError(err) {
+			t.Errorf("%s: got %v (events %v), want a verification error", tt.name, err, eventSequenceNumbers(resp.EventsWithProof))
+		}
+	}
+}
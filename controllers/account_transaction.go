@@ -0,0 +1,119 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+
+	"io.librablock.go/models"
+	"io.librablock.go/proto/types"
+	"io.librablock.go/verifier"
+)
+
+// GetAccountTransactionBySequenceNumber looks up the transaction sent by
+// address with sequenceNumber. The result carries the account's current
+// sequence number, proven by the account state, whether the transaction has
+// been committed or not. With fetchEvents the events of the transaction are
+// verified and returned too.
+func (libra *LibraRPC) GetAccountTransactionBySequenceNumber(ctx context.Context, address string, sequenceNumber uint64, fetchEvents bool) (*models.AccountTransactionModel, error) {
+	addressBytes, err := HexToBytes(address)
+	if err != nil {
+		return nil, err
+	}
+
+	// a committed transaction comes without the account state, ask for it
+	// to learn the current sequence number
+	r, err := libra.updateToLatestLedgerRequest(ctx, []*types.RequestItem{
+		libra.getAccountStateRequestMaker(addressBytes),
+		libra.getAccountTransactionBySequenceNumberRequestMaker(addressBytes, sequenceNumber, fetchEvents),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var state *types.AccountStateWithProof
+	for _, v := range r.ResponseItems {
+		switch val := v.ResponseItems.(type) {
+		case *types.ResponseItem_GetAccountStateResponse:
+			state = val.GetAccountStateResponse.AccountStateWithProof
+		case *types.ResponseItem_GetAccountTransactionBySequenceNumberResponse:
+			return libra.newAccountTransactionModel(addressBytes, sequenceNumber, fetchEvents, r.LedgerInfoWithSigs.GetLedgerInfo(), state, val.GetAccountTransactionBySequenceNumberResponse)
+		}
+	}
+
+	return nil, errors.New("missing account transaction in response")
+}
+
+func (libra *LibraRPC) newAccountTransactionModel(address []byte, sequenceNumber uint64, fetchEvents bool, ledgerInfo *types.LedgerInfo, state *types.AccountStateWithProof, resp *types.GetAccountTransactionBySequenceNumberResponse) (*models.AccountTransactionModel, error) {
+	result := models.AccountTransactionModel{}
+	result.Address = BytesToHex(address)
+	result.SequenceNumber = sequenceNumber
+	result.Verified = libra.TrustedState != nil
+
+	if ledgerInfo == nil {
+		return nil, errors.New("missing ledger info in response")
+	}
+	result.LedgerVersion = ledgerInfo.Version
+
+	if txn := resp.SignedTransactionWithProof; txn != nil {
+		if txn.SignedTransaction == nil || txn.Proof == nil {
+			return nil, errors.New("missing transaction or proof in response")
+		}
+
+		info := txn.Proof.TransactionInfo
+		err := verifier.VerifyTransactionInfo(ledgerInfo, txn.Version, info, txn.Proof.LedgerInfoToTransactionInfoProof)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := verifier.VerifySignedTransaction(info, txn.SignedTransaction); err != nil {
+			return nil, err
+		}
+
+		var events []*types.Event
+		if fetchEvents {
+			events = txn.GetEvents().GetEvents()
+			if err := verifier.VerifyEventList(info, events); err != nil {
+				return nil, err
+			}
+		}
+
+		block, err := newBlockModel(txn.Version, txn.SignedTransaction, info, events)
+		if err != nil {
+			return nil, err
+		}
+
+		if block.Source != result.Address || block.SequenceNumber != sequenceNumber {
+			return nil, verifier.Errorf("node returned transaction %s/%d for %s/%d", block.Source, block.SequenceNumber, result.Address, sequenceNumber)
+		}
+
+		account, err := libra.newAccountModel(address, ledgerInfo, state)
+		if err != nil {
+			return nil, err
+		}
+		if account == nil || account.SequenceNumber <= sequenceNumber {
+			return nil, verifier.Errorf("node returned committed transaction %d for an account that has not sent it", sequenceNumber)
+		}
+
+		result.Committed = true
+		result.Transaction = &block
+		result.Events = block.Events
+		result.AccountSequenceNumber = account.SequenceNumber
+
+		return &result, nil
+	}
+
+	account, err := libra.newAccountModel(address, ledgerInfo, resp.ProofOfCurrentSequenceNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	if account != nil {
+		result.AccountSequenceNumber = account.SequenceNumber
+	}
+
+	if result.AccountSequenceNumber > sequenceNumber {
+		return nil, verifier.Errorf("node claims sequence number %d is not committed but account is at %d", sequenceNumber, result.AccountSequenceNumber)
+	}
+
+	return &result, nil
+}
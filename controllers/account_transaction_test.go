@@ -0,0 +1,162 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"io.librablock.go/lcs"
+	"io.librablock.go/proto/types"
+	"io.librablock.go/testutil"
+	"io.librablock.go/verifier"
+)
+
+func getAccountTransaction(t *testing.T, ac *testutil.FakeAdmissionControl, address []byte, sequenceNumber uint64) (*types.LedgerInfo, *types.AccountStateWithProof, *types.GetAccountTransactionBySequenceNumberResponse) {
+	libra := LibraRPC{}
+	resp, err := ac.UpdateToLatestLedger(context.Background(), &types.UpdateToLatestLedgerRequest{
+		RequestedItems: []*types.RequestItem{
+			libra.getAccountStateRequestMaker(address),
+			libra.getAccountTransactionBySequenceNumberRequestMaker(address, sequenceNumber, true),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the fake shares its state with the response, hand out a copy
+	resp = proto.Clone(resp).(*types.UpdateToLatestLedgerResponse)
+	return resp.LedgerInfoWithSigs.LedgerInfo,
+		resp.ResponseItems[0].GetGetAccountStateResponse().AccountStateWithProof,
+		resp.ResponseItems[1].GetGetAccountTransactionBySequenceNumberResponse()
+}
+
+func TestAccountTransactionCommitted(t *testing.T) {
+	ac := testutil.PaymentLedger(t, 4)
+	libra := LibraRPC{}
+
+	ledgerInfo, state, resp := getAccountTransaction(t, ac, testutil.Alice, 2)
+	result, err := libra.newAccountTransactionModel(testutil.Alice, 2, true, ledgerInfo, state, resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the account has sent two more transactions since
+	if !result.Committed || result.Transaction.Version != 2 || result.AccountSequenceNumber != 4 {
+		t.Errorf("got %+v", result)
+	}
+	if len(result.Events) != 2 || result.Events[0].Type != SentPaymentEventType || result.Events[0].Amount != 12 {
+		t.Errorf("got events %+v", result.Events)
+	}
+}
+
+func TestAccountTransactionNotCommitted(t *testing.T) {
+	ac := testutil.PaymentLedger(t, 4)
+	libra := LibraRPC{}
+
+	ledgerInfo, state, resp := getAccountTransaction(t, ac, testutil.Alice, 4)
+	result, err := libra.newAccountTransactionModel(testutil.Alice, 4, true, ledgerInfo, state, resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Committed || result.AccountSequenceNumber != 4 {
+		t.Errorf("got %+v", result)
+	}
+}
+
+func TestAccountTransactionRejectsTampering(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(resp *types.GetAccountTransactionBySequenceNumberResponse)
+	}{
+		{"transaction", func(resp *types.GetAccountTransactionBySequenceNumberResponse) {
+			resp.SignedTransactionWithProof.SignedTransaction = fakeTransaction(t, testutil.Alice, 2, testutil.Alice, 12)
+		}},
+		{"event", func(resp *types.GetAccountTransactionBySequenceNumberResponse) {
+			resp.SignedTransactionWithProof.Events.Events[0].EventData = lcs.NewEncoder().EncodeU64(1).EncodeBytes(testutil.Bob).Result()
+		}},
+		{"missing events", func(resp *types.GetAccountTransactionBySequenceNumberResponse) {
+			resp.SignedTransactionWithProof.Events = nil
+		}},
+		{"transaction info", func(resp *types.GetAccountTransactionBySequenceNumberResponse) {
+			resp.SignedTransactionWithProof.Proof.TransactionInfo.GasUsed++
+		}},
+	}
+
+	for _, tt := range tests {
+		ac := testutil.PaymentLedger(t, 4)
+		libra := LibraRPC{}
+
+		ledgerInfo, state, resp := getAccountTransaction(t, ac, testutil.Alice, 2)
+		tt.tamper(resp)
+
+		if _, err := libra.newAccountTransactionModel(testutil.Alice, 2, true, ledgerInfo, state, resp); !verifier.IsVerificationError(err) {
+			t.Errorf("%s: got %v, want a verification error", tt.name, err)
+		}
+	}
+}
+
+func TestAccountTransactionWrongTransaction(t *testing.T) {
+	ac := testutil.PaymentLedger(t, 4)
+	libra := LibraRPC{}
+
+	// a proven transaction, but not the one asked for
+	ledgerInfo, state, resp := getAccountTransaction(t, ac, testutil.Alice, 1)
+	if _, err := libra.newAccountTransactionModel(testutil.Alice, 2, true, ledgerInfo, state, resp); !verifier.IsVerificationError(err) {
+		t.Errorf("got %v, want a verification error", err)
+	}
+}
+
+func TestAccountTransactionMissingButAccountPast(t *testing.T) {
+	ac := testutil.NewFakeAdmissionControl()
+	_, err := ac.Commit(testutil.FakeTransaction{
+		Transaction: fakeTransaction(t, testutil.Bob, 0, testutil.Bob, 0),
+		Accounts: map[string][]byte{
+			string(testutil.Alice): testutil.AccountBlob(&lcs.AccountResource{SequenceNumber: 10}),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	libra := LibraRPC{}
+
+	ledgerInfo, state, resp := getAccountTransaction(t, ac, testutil.Alice, 3)
+	if _, err := libra.newAccountTransactionModel(testutil.Alice, 3, true, ledgerInfo, state, resp); !verifier.IsVerificationError(err) {
+		t.Errorf("got %v, want a verification error", err)
+	}
+}
+
+func TestAccountTransactionCommittedChecksAccount(t *testing.T) {
+	ac := testutil.PaymentLedger(t, 4)
+	libra := LibraRPC{}
+
+	ledgerInfo, _, resp := getAccountTransaction(t, ac, testutil.Alice, 2)
+	if _, err := libra.newAccountTransactionModel(testutil.Alice, 2, true, ledgerInfo, nil, resp); err == nil {
+		t.Error("accepted a committed transaction without the account state")
+	}
+
+	// bob's proven state, which has sent nothing
+	_, state, _ := getAccountTransaction(t, ac, testutil.Bob, 2)
+	if _, err := libra.newAccountTransactionModel(testutil.Alice, 2, true, ledgerInfo, state, resp); !verifier.IsVerificationError(err) {
+		t.Errorf("got %v, want a verification error", err)
+	}
+}
+
+func TestAccountTransactionCommittedAccountBehind(t *testing.T) {
+	ac := testutil.NewFakeAdmissionControl()
+	_, err := ac.Commit(testutil.FakeTransaction{
+		Transaction: fakeTransaction(t, testutil.Alice, 2, testutil.Bob, 1),
+		Accounts: map[string][]byte{
+			string(testutil.Alice): testutil.AccountBlob(&lcs.AccountResource{SequenceNumber: 2}),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	libra := LibraRPC{}
+
+	ledgerInfo, state, resp := getAccountTransaction(t, ac, testutil.Alice, 2)
+	if _, err := libra.newAccountTransactionModel(testutil.Alice, 2, true, ledgerInfo, state, resp); !verifier.IsVerificationError(err) {
+		t.Errorf("got %v, want a verification error", err)
+	}
+}
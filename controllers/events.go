@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"bytes"
+	"errors"
+
+	"io.librablock.go/lcs"
+)
+
+const (
+	SentPaymentEventType     = "sent_payment"
+	ReceivedPaymentEventType = "received_payment"
+	UnknownEventType         = "unknown"
+
+	addressLength = 32
+)
+
+type PaymentEvent struct {
+	Amount       uint64
+	Counterparty string
+}
+
+// DecodePaymentEvent decodes the payload shared by sent and received payment
+// events: the amount as a little endian u64 followed by the counterparty
+// address, which may carry a u32 length prefix.
+func DecodePaymentEvent(data []byte) (*PaymentEvent, error) {
+	d := lcs.NewDecoder(data)
+	event := PaymentEvent{}
+
+	amount, err := d.DecodeU64()
+	if err != nil {
+		return nil, err
+	}
+	event.Amount = amount
+
+	var address []byte
+	if d.Remaining() == addressLength {
+		address, err = d.DecodeFixedBytes(addressLength)
+	} else {
+		address, err = d.DecodeBytes()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(address) != addressLength {
+		return nil, errors.New("bad payment event address length")
+	}
+	if err := d.Finish(); err != nil {
+		return nil, err
+	}
+
+	event.Counterparty = BytesToHex(address)
+	return &event, nil
+}
+
+// PaymentEventType tells the sent and received payment event handles of an
+// account apart by their key. Events of any other handle are unknown, whatever
+// their payload looks like.
+func PaymentEventType(key []byte) string {
+	if len(key) < addressLength {
+		return UnknownEventType
+	}
+
+	address := key[:addressLength]
+	switch {
+	case bytes.Equal(key, lcs.AccountEventKey(address, lcs.SentEventsPath)):
+		return SentPaymentEventType
+	case bytes.Equal(key, lcs.AccountEventKey(address, lcs.ReceivedEventsPath)):
+		return ReceivedPaymentEventType
+	default:
+		return UnknownEventType
+	}
+}
@@ -0,0 +1,56 @@
+package controllers
+
+import (
+	"bytes"
+	"testing"
+
+	"io.librablock.go/lcs"
+	"io.librablock.go/proto/types"
+)
+
+func TestPaymentEventType(t *testing.T) {
+	address := bytes.Repeat([]byte{0x11}, addressLength)
+
+	tests := []struct {
+		name string
+		key  []byte
+		want string
+	}{
+		{"sent", lcs.AccountEventKey(address, lcs.SentEventsPath), SentPaymentEventType},
+		{"received", lcs.AccountEventKey(address, lcs.ReceivedEventsPath), ReceivedPaymentEventType},
+		{"other path", lcs.AccountEventKey(address, "/other_events_count/"), UnknownEventType},
+		{"path suffix only", append([]byte{0x01}, lcs.AccountEventKey(address, lcs.SentEventsPath)...), UnknownEventType},
+		{"short key", address[:8], UnknownEventType},
+	}
+
+	for _, tt := range tests {
+		if got := PaymentEventType(tt.key); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNewEventModelSelfPayment(t *testing.T) {
+	address := bytes.Repeat([]byte{0x22}, addressLength)
+	data := lcs.NewEncoder().EncodeU64(7).EncodeBytes(address).Result()
+
+	for path, want := range map[string]string{
+		lcs.SentEventsPath:     SentPaymentEventType,
+		lcs.ReceivedEventsPath: ReceivedPaymentEventType,
+	} {
+		event := newEventModel(1, 0, &types.Event{Key: lcs.AccountEventKey(address, path), EventData: data})
+		if event.Type != want || event.Amount != 7 || event.Counterparty != BytesToHex(address) {
+			t.Errorf("%s: got %+v", path, event)
+		}
+	}
+}
+
+func TestNewEventModelPaymentShapedPayload(t *testing.T) {
+	address := bytes.Repeat([]byte{0x33}, addressLength)
+	data := lcs.NewEncoder().EncodeU64(7).EncodeBytes(address).Result()
+
+	event := newEventModel(1, 0, &types.Event{Key: []byte("some other handle"), EventData: data})
+	if event.Type != UnknownEventType || event.Amount != 0 {
+		t.Errorf("got %+v", event)
+	}
+}
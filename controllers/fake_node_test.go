@@ -0,0 +1,204 @@
+package controllers_test
+
+import (
+	"context"
+	"testing"
+
+	"io.librablock.go/controllers"
+	"io.librablock.go/proto/admission_control"
+	"io.librablock.go/proto/types"
+	"io.librablock.go/testutil"
+	"io.librablock.go/testutil/fakerpc"
+	"io.librablock.go/verifier"
+)
+
+// these tests talk to the fake node over grpc, through the exported API only
+
+func paymentTransaction(t *testing.T, sender []byte, sequenceNumber uint64, receiver []byte, amount uint64) *types.SignedTransaction {
+	txn, err := testutil.PaymentTransaction(sender, sequenceNumber, receiver, amount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return txn
+}
+
+func TestLibraRPCFakeNode(t *testing.T) {
+	ac := testutil.PaymentLedger(t, 5)
+	ac.Validators = testutil.FakeValidators(t, 4)
+	libra, stop := fakerpc.Dial(t, ac)
+	defer stop()
+	ctx := context.Background()
+
+	latest, err := libra.GetLatestVersion(ctx)
+	if err != nil || latest != 4 {
+		t.Fatalf("GetLatestVersion = %d, %v", latest, err)
+	}
+
+	blocks, err := libra.GetTransactions(ctx, 1, 3, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*blocks) != 3 || (*blocks)[0].Version != 1 || len((*blocks)[2].Events) != 2 {
+		t.Errorf("GetTransactions = %+v", *blocks)
+	}
+
+	account, err := libra.GetAccountState(ctx, controllers.BytesToHex(testutil.Alice))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if account == nil || account.SequenceNumber != 5 || account.SentEventCount != 5 || !account.Verified {
+		t.Errorf("GetAccountState = %+v", account)
+	}
+
+	missing, err := libra.GetAccountState(ctx, controllers.BytesToHex(make([]byte, 32)))
+	if err != nil || missing != nil {
+		t.Errorf("GetAccountState of a missing account = %+v, %v", missing, err)
+	}
+
+	events, err := libra.GetAccountEvents(ctx, controllers.BytesToHex(testutil.Bob), controllers.ReceivedPaymentEventType, controllers.LatestEventSequenceNumber, false, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events.Events) != 2 || events.Events[0].SequenceNumber != 4 || *events.EventCount != 5 {
+		t.Errorf("GetAccountEvents = %+v", events)
+	}
+
+	txn, err := libra.GetAccountTransactionBySequenceNumber(ctx, controllers.BytesToHex(testutil.Alice), 3, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !txn.Committed || txn.Transaction.Version != 3 || len(txn.Events) != 2 {
+		t.Errorf("GetAccountTransactionBySequenceNumber = %+v", txn)
+	}
+
+	submitted, err := libra.SubmitTransaction(ctx, paymentTransaction(t, testutil.Alice, 5, testutil.Bob, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !submitted.Accepted || len(ac.Submitted()) != 1 {
+		t.Errorf("SubmitTransaction = %+v", submitted)
+	}
+}
+
+func TestLibraRPCFakeNodeRejected(t *testing.T) {
+	tests := []struct {
+		name   string
+		status *admission_control.SubmitTransactionResponse
+		source string
+		code   string
+	}{
+		{
+			"admission control",
+			&admission_control.SubmitTransactionResponse{
+				Status: &admission_control.SubmitTransactionResponse_AcStatus{
+					AcStatus: &admission_control.AdmissionControlStatus{
+						Code:    admission_control.AdmissionControlStatusCode_Rejected,
+						Message: "no",
+					},
+				},
+			},
+			controllers.AdmissionControlErrorSource, "Rejected",
+		},
+		{
+			"vm validation",
+			&admission_control.SubmitTransactionResponse{
+				Status: &admission_control.SubmitTransactionResponse_VmStatus{
+					VmStatus: &types.VMStatus{
+						ErrorType: &types.VMStatus_Validation{
+							Validation: &types.VMValidationStatus{Code: types.VMValidationStatusCode_SequenceNumberTooOld},
+						},
+					},
+				},
+			},
+			controllers.VMErrorSource, "SequenceNumberTooOld",
+		},
+		{
+			"vm status without an error",
+			&admission_control.SubmitTransactionResponse{
+				Status: &admission_control.SubmitTransactionResponse_VmStatus{VmStatus: &types.VMStatus{}},
+			},
+			controllers.VMErrorSource, "Unknown",
+		},
+	}
+
+	for _, tt := range tests {
+		ac := testutil.PaymentLedger(t, 1)
+		ac.SubmitResponse = tt.status
+		libra, stop := fakerpc.Dial(t, ac)
+
+		submitted, err := libra.SubmitTransaction(context.Background(), paymentTransaction(t, testutil.Alice, 1, testutil.Bob, 1))
+		stop()
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if submitted.Accepted || submitted.Error == nil || submitted.Error.Source != tt.source || submitted.Error.Code != tt.code {
+			t.Errorf("%s: SubmitTransaction = %+v, %+v", tt.name, submitted, submitted.Error)
+		}
+	}
+}
+
+func TestLibraRPCFakeNodeUntrustedValidators(t *testing.T) {
+	ac := testutil.PaymentLedger(t, 2)
+	ac.Validators = testutil.FakeValidators(t, 4)
+	libra, stop := fakerpc.Dial(t, ac)
+	defer stop()
+
+	// the node signs with validators the client does not know
+	ac.Validators = testutil.FakeValidators(t, 4)
+
+	if _, err := libra.GetLatestVersion(context.Background()); !verifier.IsVerificationError(err) {
+		t.Errorf("got %v, want a verification error", err)
+	}
+}
+
+func TestAccountStateByVersion(t *testing.T) {
+	ac := testutil.PaymentLedger(t, 5)
+	ac.Validators = testutil.FakeValidators(t, 4)
+	libra, stop := fakerpc.DialWithStorage(t, ac, ac)
+	defer stop()
+	ctx := context.Background()
+
+	// alice has sent payments 0, 1 and 2 by version 2
+	account, err := libra.GetAccountStateByVersion(ctx, controllers.BytesToHex(testutil.Alice), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if account == nil || account.SequenceNumber != 3 || account.Version != 2 || account.LedgerVersion != 4 || !account.Verified {
+		t.Errorf("got %+v", account)
+	}
+
+	missing, err := libra.GetAccountStateByVersion(ctx, controllers.BytesToHex(make([]byte, 32)), 2)
+	if err != nil || missing != nil {
+		t.Errorf("got %+v, %v for a missing account", missing, err)
+	}
+
+	if _, err := libra.GetAccountStateByVersion(ctx, controllers.BytesToHex(testutil.Alice), 5); err == nil || verifier.IsVerificationError(err) {
+		t.Errorf("got %v for a version past the ledger", err)
+	}
+}
+
+func TestAccountStateByVersionRejectsStorage(t *testing.T) {
+	ac := testutil.PaymentLedger(t, 5)
+
+	// a storage service with another history
+	forged := testutil.NewFakeAdmissionControl()
+	if err := forged.CommitPayments(testutil.Bob, testutil.Alice, 5); err != nil {
+		t.Fatal(err)
+	}
+
+	libra, stop := fakerpc.DialWithStorage(t, ac, forged)
+	defer stop()
+
+	if _, err := libra.GetAccountStateByVersion(context.Background(), controllers.BytesToHex(testutil.Alice), 2); !verifier.IsVerificationError(err) {
+		t.Errorf("got %v, want a verification error", err)
+	}
+}
+
+func TestAccountStateByVersionWithoutStorage(t *testing.T) {
+	libra, stop := fakerpc.Dial(t, testutil.PaymentLedger(t, 2))
+	defer stop()
+
+	if _, err := libra.GetAccountStateByVersion(context.Background(), controllers.BytesToHex(testutil.Alice), 1); err != controllers.ErrNoStorage {
+		t.Errorf("got %v, want controllers.ErrNoStorage", err)
+	}
+}
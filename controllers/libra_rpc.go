@@ -5,17 +5,19 @@ import (
 	"crypto/md5"
 	"encoding/binary"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io.librablock.go/proto/admission_control"
+	"io.librablock.go/proto/storage"
 	"io.librablock.go/proto/types"
-	"log"
-	"strings"
 	"time"
 
 	"github.com/golang/protobuf/proto"
 	"google.golang.org/grpc"
 
+	"io.librablock.go/lcs"
 	"io.librablock.go/models"
+	"io.librablock.go/verifier"
 )
 
 const (
@@ -28,18 +30,55 @@ const (
 )
 
 type LibraRPC struct {
-	Address string
+	Address      string
+	Options      RPCOptions
+	VerifyProofs bool
+	TrustedState *verifier.TrustedState
+
+	conn   *grpc.ClientConn
+	client admission_control.AdmissionControlClient
+
+	storageConn *grpc.ClientConn
+	storage     storage.StorageClient
 }
 
-func NewLibraRPC(address *string) LibraRPC {
-	l := LibraRPC{}
+// NewLibraRPC sets up a single connection to the node that is shared by every
+// call and re-established by grpc whenever it drops. Call Close when done.
+func NewLibraRPC(address *string, options RPCOptions) (*LibraRPC, error) {
+	l := LibraRPC{Options: options}
 	if address != nil {
 		l.Address = *address
 	} else {
 		l.Address = DefaultAddress
 	}
 
-	return l
+	conn, err := grpc.Dial(l.Address, options.DialOptions()...)
+	if err != nil {
+		return nil, err
+	}
+
+	l.conn = conn
+	l.client = admission_control.NewAdmissionControlClient(conn)
+
+	if options.StorageAddress != "" {
+		storageConn, err := grpc.Dial(options.StorageAddress, options.DialOptions()...)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+
+		l.storageConn = storageConn
+		l.storage = storage.NewStorageClient(storageConn)
+	}
+
+	return &l, nil
+}
+
+func (libra *LibraRPC) Close() error {
+	if libra.storageConn != nil {
+		libra.storageConn.Close()
+	}
+	return libra.conn.Close()
 }
 
 func BytesToHex(bytes []byte) string {
@@ -62,8 +101,8 @@ func HexToUint64(str string) (uint64, error) {
 	return uint64(binary.LittleEndian.Uint64(bytes)), nil
 }
 
-func (libra LibraRPC) GetLatestVersion() (uint64, error) {
-	r, err := libra.updateToLatestLedgerRequest([]*types.RequestItem{libra.getTransactionsRequestMaker(0, 1, false)})
+func (libra *LibraRPC) GetLatestVersion(ctx context.Context) (uint64, error) {
+	r, err := libra.updateToLatestLedgerRequest(ctx, []*types.RequestItem{libra.getTransactionsRequestMaker(0, 1, false)})
 
 	if err != nil {
 		return 0, err
@@ -72,8 +111,8 @@ func (libra LibraRPC) GetLatestVersion() (uint64, error) {
 	return r.LedgerInfoWithSigs.LedgerInfo.Version, nil
 }
 
-func (libra LibraRPC) GetTransactions(version uint64, limit uint64, fetchEvents bool) (*[]models.BlockModel, error) {
-	r, err := libra.updateToLatestLedgerRequest([]*types.RequestItem{libra.getTransactionsRequestMaker(version, limit, false)})
+func (libra *LibraRPC) GetTransactions(ctx context.Context, version uint64, limit uint64, fetchEvents bool) (*[]models.BlockModel, error) {
+	r, err := libra.updateToLatestLedgerRequest(ctx, []*types.RequestItem{libra.getTransactionsRequestMaker(version, limit, fetchEvents)})
 
 	if err != nil {
 		fmt.Println(r)
@@ -81,70 +120,172 @@ func (libra LibraRPC) GetTransactions(version uint64, limit uint64, fetchEvents
 		return nil, err
 	}
 
-	var res []models.BlockModel
-
 	for _, x := range r.ResponseItems {
 		switch val := x.ResponseItems.(type) {
 		case *types.ResponseItem_GetTransactionsResponse:
-			transactions := val.GetTransactionsResponse.TxnListWithProof.Transactions
-			for idx, trans := range transactions {
-				raw := types.RawTransaction{}
-				err := proto.Unmarshal(trans.RawTxnBytes, &raw)
-				if err != nil {
+			return libra.newBlockModels(r.LedgerInfoWithSigs.GetLedgerInfo(), version, fetchEvents, val.GetTransactionsResponse)
+		}
+	}
+
+	return &[]models.BlockModel{}, nil
+}
+
+func (libra *LibraRPC) newBlockModels(ledgerInfo *types.LedgerInfo, version uint64, fetchEvents bool, resp *types.GetTransactionsResponse) (*[]models.BlockModel, error) {
+	var res []models.BlockModel
+
+	list := resp.TxnListWithProof
+	if list == nil {
+		return &res, nil
+	}
+
+	transactions := list.Transactions
+	infos := list.Infos
+	if len(infos) != len(transactions) {
+		return nil, fmt.Errorf("got %d transaction infos for %d transactions", len(infos), len(transactions))
+	}
+
+	if libra.VerifyProofs {
+		err := libra.verifyTransactionList(ledgerInfo, version, list)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var eventLists []*types.EventsList
+	if fetchEvents {
+		eventLists = list.GetEventsForVersions().GetEventsForVersion()
+		if len(eventLists) != len(transactions) {
+			return nil, fmt.Errorf("got %d event lists for %d transactions", len(eventLists), len(transactions))
+		}
+	}
+
+	for idx, trans := range transactions {
+		var events []*types.Event
+		if fetchEvents {
+			events = eventLists[idx].GetEvents()
+		}
+
+		if libra.VerifyProofs {
+			// the accumulator only proves the infos, which in turn commit
+			// to the transaction and its events
+			if err := verifier.VerifySignedTransaction(infos[idx], trans); err != nil {
+				return nil, err
+			}
+			if fetchEvents {
+				if err := verifier.VerifyEventList(infos[idx], events); err != nil {
 					return nil, err
 				}
+			}
+		}
 
-				result := models.BlockModel{}
-
-				result.Version = version + uint64(idx)
-				result.ExpirationAt = time.Unix(int64(raw.ExpirationTime), 0)
-				result.Source = BytesToHex(raw.SenderAccount)
-				result.GasPrice = raw.GasUnitPrice
-				result.MaxGas = raw.MaxGasAmount
-				result.SequenceNumber = raw.SequenceNumber
-				result.PublicKey = BytesToHex(trans.SenderPublicKey)
-
-				switch payload := raw.Payload.(type) {
-				case *types.RawTransaction_Program:
-					for _, arg := range payload.Program.Arguments {
-						switch arg.Type {
-						case types.TransactionArgument_U64:
-							result.Amount = uint64(binary.LittleEndian.Uint64(arg.Data))
-						case types.TransactionArgument_ADDRESS:
-							result.Destination = BytesToHex(arg.Data)
-						}
-					}
-					has := md5.Sum(payload.Program.Code)
-					result.MD5 = fmt.Sprintf("%x", has)
-
-					if result.MD5 == P2pProgramMd5 {
-						result.Type = P2pTransType
-					} else if result.MD5 == MintProgramMd5 {
-						result.Type = MintTransType
-					} else {
-						result.Type = UnknownTransType
-					}
+		result, err := newBlockModel(version+uint64(idx), trans, infos[idx], events)
+		if err != nil {
+			return nil, err
+		}
 
-				}
+		res = append(res, result)
+	}
+
+	return &res, nil
+}
 
-				res = append(res, result)
+func newBlockModel(version uint64, trans *types.SignedTransaction, info *types.TransactionInfo, events []*types.Event) (models.BlockModel, error) {
+	result := models.BlockModel{}
+
+	raw := types.RawTransaction{}
+	err := proto.Unmarshal(trans.RawTxnBytes, &raw)
+	if err != nil {
+		return result, err
+	}
+
+	result.Version = version
+	result.ExpirationAt = time.Unix(int64(raw.ExpirationTime), 0)
+	result.Source = BytesToHex(raw.SenderAccount)
+	result.GasPrice = raw.GasUnitPrice
+	result.MaxGas = raw.MaxGasAmount
+	result.SequenceNumber = raw.SequenceNumber
+	result.PublicKey = BytesToHex(trans.SenderPublicKey)
+
+	result.GasUsed = info.GasUsed
+	result.SignedTransactionHash = BytesToHex(info.SignedTransactionHash)
+	result.StateRootHash = BytesToHex(info.StateRootHash)
+	result.EventRootHash = BytesToHex(info.EventRootHash)
+
+	for eventIdx, event := range events {
+		result.Events = append(result.Events, newEventModel(result.Version, uint64(eventIdx), event))
+	}
+
+	switch payload := raw.Payload.(type) {
+	case *types.RawTransaction_Program:
+		for _, arg := range payload.Program.Arguments {
+			switch arg.Type {
+			case types.TransactionArgument_U64:
+				result.Amount = uint64(binary.LittleEndian.Uint64(arg.Data))
+			case types.TransactionArgument_ADDRESS:
+				result.Destination = BytesToHex(arg.Data)
 			}
 		}
+		has := md5.Sum(payload.Program.Code)
+		result.MD5 = fmt.Sprintf("%x", has)
+
+		if result.MD5 == P2pProgramMd5 {
+			result.Type = P2pTransType
+		} else if result.MD5 == MintProgramMd5 {
+			result.Type = MintTransType
+		} else {
+			result.Type = UnknownTransType
+		}
+
 	}
 
-	return &res, nil
+	return result, nil
 }
 
-func (libra LibraRPC) GetAccountState(address string) (*models.AccountModel, error) {
-	result := models.AccountModel{}
-	result.Address = address
+func (libra *LibraRPC) verifyTransactionList(ledgerInfo *types.LedgerInfo, version uint64, list *types.TransactionListWithProof) error {
+	if len(list.Transactions) == 0 {
+		return nil
+	}
+
+	if list.FirstTransactionVersion == nil || list.FirstTransactionVersion.Value != version {
+		return fmt.Errorf("transaction list does not start at requested version %d", version)
+	}
+
+	return verifier.VerifyTransactionList(ledgerInfo, version, list.Infos, list.ProofOfFirstTransaction, list.ProofOfLastTransaction)
+}
+
+func newEventModel(version uint64, index uint64, event *types.Event) models.EventModel {
+	result := models.EventModel{
+		Version:        version,
+		EventIndex:     index,
+		Key:            BytesToHex(event.Key),
+		SequenceNumber: event.SequenceNumber,
+		Data:           BytesToHex(event.EventData),
+		Type:           UnknownEventType,
+	}
+
+	eventType := PaymentEventType(event.Key)
+	if eventType == UnknownEventType {
+		return result
+	}
+
+	payment, err := DecodePaymentEvent(event.EventData)
+	if err == nil {
+		result.Type = eventType
+		result.Amount = payment.Amount
+		result.Counterparty = payment.Counterparty
+	}
+
+	return result
+}
+
+func (libra *LibraRPC) GetAccountState(ctx context.Context, address string) (*models.AccountModel, error) {
 	addressBytes, err := HexToBytes(address)
 
 	if err != nil {
 		return nil, err
 	}
 
-	r, err := libra.updateToLatestLedgerRequest([]*types.RequestItem{libra.getAccountStateRequestMaker(addressBytes)})
+	r, err := libra.updateToLatestLedgerRequest(ctx, []*types.RequestItem{libra.getAccountStateRequestMaker(addressBytes)})
 
 	if err != nil {
 		return nil, err
@@ -153,79 +294,137 @@ func (libra LibraRPC) GetAccountState(address string) (*models.AccountModel, err
 	for _, v := range r.ResponseItems {
 		switch val := v.ResponseItems.(type) {
 		case *types.ResponseItem_GetAccountStateResponse:
-			blob := val.GetAccountStateResponse.AccountStateWithProof.Blob
-			if blob.GetBlob() == nil {
-				return nil, nil
-			}
+			return libra.newAccountModel(addressBytes, r.LedgerInfoWithSigs.GetLedgerInfo(), val.GetAccountStateResponse.AccountStateWithProof)
+		}
+	}
+	return nil, errors.New("missing account state in response")
+}
 
-			str := BytesToHex(blob.Blob)
-			magicStr := "100000001217da6c6b3e19f1825cfb2676daecce3bf3de03cf26647c78df00b371b25cc974500000020000000"
-			idx := strings.Index(str, magicStr)
-			it := len(magicStr) + idx
-			addressLength := 64
-			result.AuthenticationKey = str[it : it+addressLength]
-
-			it += addressLength
-			bitLength := 16
-			var tmpArr []uint64
-			for i := 0; i < 4; i++ {
-				data, err := HexToUint64(str[it : it+bitLength])
-				if err != nil {
-					return nil, err
-				}
+// newAccountModel verifies state against ledgerInfo and decodes it. It
+// returns nil when the proof shows that the account does not exist.
+func (libra *LibraRPC) newAccountModel(address []byte, ledgerInfo *types.LedgerInfo, state *types.AccountStateWithProof) (*models.AccountModel, error) {
+	if state == nil {
+		return nil, errors.New("missing account state in response")
+	}
 
-				it += bitLength
+	blob := state.Blob
+	err := verifier.VerifyAccountState(ledgerInfo, state.Version, address, blob.GetBlob(), state.Proof)
+	if err != nil {
+		return nil, err
+	}
 
-				if i == 0 { //skip for a boolean param
-					it += 2
-				}
+	if blob.GetBlob() == nil {
+		return nil, nil
+	}
 
-				tmpArr = append(tmpArr, data)
-			}
+	result, err := decodeAccountModel(address, blob.Blob)
+	if err != nil {
+		return nil, err
+	}
 
-			result.Balance = tmpArr[0]
-			result.ReceivedEventCount = tmpArr[1]
-			result.SentEventCount = tmpArr[2]
-			result.SequenceNumber = tmpArr[3]
+	result.Verified = libra.TrustedState != nil
+	result.Version = state.Version
+	result.LedgerVersion = ledgerInfo.Version
+	result.StateRootHash = BytesToHex(state.Proof.TransactionInfo.StateRootHash)
 
-			break
-		}
+	return result, nil
+}
+
+func decodeAccountModel(address []byte, blob []byte) (*models.AccountModel, error) {
+	resource, err := lcs.AccountResourceFromBlob(blob)
+	if err != nil {
+		return nil, err
 	}
+
+	result := models.AccountModel{}
+	result.Address = BytesToHex(address)
+
+	result.AuthenticationKey = BytesToHex(resource.AuthenticationKey)
+	result.Balance = resource.Balance
+	result.ReceivedEventCount = resource.ReceivedEventsCount
+	result.SentEventCount = resource.SentEventsCount
+	result.SequenceNumber = resource.SequenceNumber
+
 	return &result, nil
 }
 
-func (libra LibraRPC) updateToLatestLedgerRequest(requests []*types.RequestItem) (*types.UpdateToLatestLedgerResponse, error) {
-	conn, rpcErr := grpc.Dial(libra.Address, grpc.WithInsecure())
-	if rpcErr != nil {
-		log.Fatalf("did not connect: %v", rpcErr)
+// callContext bounds a single call by Options.Timeout, unless it is zero.
+func (libra *LibraRPC) callContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if libra.Options.Timeout <= 0 {
+		return context.WithCancel(ctx)
 	}
-	defer conn.Close()
+	return context.WithTimeout(ctx, libra.Options.Timeout)
+}
 
-	c := admission_control.NewAdmissionControlClient(conn)
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+func (libra *LibraRPC) updateToLatestLedgerRequest(ctx context.Context, requests []*types.RequestItem) (*types.UpdateToLatestLedgerResponse, error) {
+	ctx, cancel := libra.callContext(ctx)
 	defer cancel()
 
-	return c.UpdateToLatestLedger(
+	var knownVersion uint64
+	if libra.TrustedState != nil {
+		knownVersion = libra.TrustedState.KnownVersion()
+	}
+
+	r, err := libra.client.UpdateToLatestLedger(
 		ctx,
 		&types.UpdateToLatestLedgerRequest{
-			ClientKnownVersion: 0,
+			ClientKnownVersion: knownVersion,
 			RequestedItems:     requests,
 		},
 	)
+	if err != nil {
+		return nil, err
+	}
+
+	if libra.TrustedState != nil {
+		if err := libra.TrustedState.Verify(r); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
 }
 
-func (libra LibraRPC) getTransactionsRequestMaker(version uint64, limit uint64, fetchEvents bool) *types.RequestItem {
+func (libra *LibraRPC) getTransactionsRequestMaker(version uint64, limit uint64, fetchEvents bool) *types.RequestItem {
 	return &types.RequestItem{
 		RequestedItems: &types.RequestItem_GetTransactionsRequest{
 			GetTransactionsRequest: &types.GetTransactionsRequest{
 				StartVersion: version,
 				Limit:        limit,
-				FetchEvents:  true,
+				FetchEvents:  fetchEvents,
 			},
 		}}
 }
 
-func (libra LibraRPC) getAccountStateRequestMaker(address []byte) *types.RequestItem {
+func (libra *LibraRPC) getAccountTransactionBySequenceNumberRequestMaker(address []byte, sequenceNumber uint64, fetchEvents bool) *types.RequestItem {
+	return &types.RequestItem{
+		RequestedItems: &types.RequestItem_GetAccountTransactionBySequenceNumberRequest{
+			GetAccountTransactionBySequenceNumberRequest: &types.GetAccountTransactionBySequenceNumberRequest{
+				Account:        address,
+				SequenceNumber: sequenceNumber,
+				FetchEvents:    fetchEvents,
+			},
+		},
+	}
+}
+
+func (libra *LibraRPC) getEventsByEventAccessPathRequestMaker(address []byte, path []byte, start uint64, ascending bool, limit uint64) *types.RequestItem {
+	return &types.RequestItem{
+		RequestedItems: &types.RequestItem_GetEventsByEventAccessPathRequest{
+			GetEventsByEventAccessPathRequest: &types.GetEventsByEventAccessPathRequest{
+				AccessPath: &types.AccessPath{
+					Address: address,
+					Path:    path,
+				},
+				StartEventSeqNum: start,
+				Ascending:        ascending,
+				Limit:            limit,
+			},
+		},
+	}
+}
+
+func (libra *LibraRPC) getAccountStateRequestMaker(address []byte) *types.RequestItem {
 	return &types.RequestItem{
 		RequestedItems: &types.RequestItem_GetAccountStateRequest{
 			GetAccountStateRequest: &types.GetAccountStateRequest{
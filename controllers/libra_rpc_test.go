@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"io.librablock.go/lcs"
+	"io.librablock.go/proto/types"
+	"io.librablock.go/testutil"
+	"io.librablock.go/verifier"
+)
+
+func fakeTransaction(t *testing.T, sender []byte, sequenceNumber uint64, receiver []byte, amount uint64) *types.SignedTransaction {
+	txn, err := testutil.PaymentTransaction(sender, sequenceNumber, receiver, amount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return txn
+}
+
+func getTransactions(t *testing.T, ac *testutil.FakeAdmissionControl, start uint64, limit uint64) (*types.LedgerInfo, *types.GetTransactionsResponse) {
+	resp, err := ac.UpdateToLatestLedger(context.Background(), &types.UpdateToLatestLedgerRequest{
+		RequestedItems: []*types.RequestItem{(&LibraRPC{}).getTransactionsRequestMaker(start, limit, true)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return resp.LedgerInfoWithSigs.LedgerInfo, resp.ResponseItems[0].GetGetTransactionsResponse()
+}
+
+func TestNewBlockModelsVerified(t *testing.T) {
+	ac := testutil.PaymentLedger(t, 5)
+	libra := LibraRPC{VerifyProofs: true}
+
+	ledgerInfo, resp := getTransactions(t, ac, 1, 3)
+	blocks, err := libra.newBlockModels(ledgerInfo, 1, true, resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(*blocks) != 3 {
+		t.Fatalf("got %d blocks", len(*blocks))
+	}
+	for i, block := range *blocks {
+		if block.Version != uint64(1+i) || block.Source != BytesToHex(testutil.Alice) || block.Destination != BytesToHex(testutil.Bob) || block.Amount != uint64(11+i) {
+			t.Errorf("block %d: got %+v", i, block)
+		}
+		if len(block.Events) != 2 || block.Events[0].Type != SentPaymentEventType || block.Events[1].Type != ReceivedPaymentEventType {
+			t.Errorf("block %d: got events %+v", i, block.Events)
+		}
+	}
+}
+
+func TestNewBlockModelsRejectsTampering(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(ledgerInfo *types.LedgerInfo, list *types.TransactionListWithProof)
+	}{
+		{"transaction amount", func(_ *types.LedgerInfo, list *types.TransactionListWithProof) {
+			raw := types.RawTransaction{}
+			proto.Unmarshal(list.Transactions[1].RawTxnBytes, &raw)
+			raw.GetProgram().Arguments[1].Data = lcs.NewEncoder().EncodeU64(1000000).Result()
+			list.Transactions[1].RawTxnBytes, _ = proto.Marshal(&raw)
+		}},
+		{"transaction public key", func(_ *types.LedgerInfo, list *types.TransactionListWithProof) {
+			list.Transactions[0].SenderPublicKey = bytes.Repeat([]byte{0x03}, 32)
+		}},
+		{"event data", func(_ *types.LedgerInfo, list *types.TransactionListWithProof) {
+			event := list.EventsForVersions.EventsForVersion[2].Events[0]
+			event.EventData = lcs.NewEncoder().EncodeU64(1000000).EncodeBytes(testutil.Bob).Result()
+		}},
+		{"dropped event", func(_ *types.LedgerInfo, list *types.TransactionListWithProof) {
+			events := list.EventsForVersions.EventsForVersion[0]
+			events.Events = events.Events[:1]
+		}},
+		{"event key", func(_ *types.LedgerInfo, list *types.TransactionListWithProof) {
+			list.EventsForVersions.EventsForVersion[0].Events[1].Key = lcs.AccountEventKey(testutil.Alice, lcs.ReceivedEventsPath)
+		}},
+		{"transaction info", func(_ *types.LedgerInfo, list *types.TransactionListWithProof) {
+			list.Infos[1].GasUsed++
+		}},
+		{"proof sibling", func(_ *types.LedgerInfo, list *types.TransactionListWithProof) {
+			siblings := list.ProofOfFirstTransaction.NonDefaultSiblings
+			siblings[len(siblings)-1][0] ^= 1
+		}},
+		{"proof bitmap", func(_ *types.LedgerInfo, list *types.TransactionListWithProof) {
+			list.ProofOfLastTransaction.Bitmap <<= 1
+		}},
+		{"ledger accumulator hash", func(ledgerInfo *types.LedgerInfo, _ *types.TransactionListWithProof) {
+			ledgerInfo.TransactionAccumulatorHash[0] ^= 1
+		}},
+		{"ledger version", func(ledgerInfo *types.LedgerInfo, _ *types.TransactionListWithProof) {
+			ledgerInfo.Version = 2
+		}},
+	}
+
+	for _, tt := range tests {
+		ac := testutil.PaymentLedger(t, 5)
+		libra := LibraRPC{VerifyProofs: true}
+
+		ledgerInfo, resp := getTransactions(t, ac, 1, 3)
+		// the fake shares its state with the response, work on a copy
+		ledgerInfo = proto.Clone(ledgerInfo).(*types.LedgerInfo)
+		resp = proto.Clone(resp).(*types.GetTransactionsResponse)
+		tt.tamper(ledgerInfo, resp.TxnListWithProof)
+
+		_, err := libra.newBlockModels(ledgerInfo, 1, true, resp)
+		if !verifier.IsVerificationError(err) {
+			t.Errorf("%s: got %v, want a verification error", tt.name, err)
+		}
+	}
+}
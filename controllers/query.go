@@ -0,0 +1,204 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"io.librablock.go/models"
+	"io.librablock.go/proto/types"
+)
+
+// Query batches several requests into a single UpdateToLatestLedger round
+// trip. Every method adds its items and returns the result they will be
+// written to once Execute succeeds; all results are proven against the same
+// ledger info.
+//
+//	q := rpc.NewQuery()
+//	account := q.AccountState(address)
+//	sent := q.AccountEvents(address, SentPaymentEventType, LatestEventSequenceNumber, false, 10)
+//	err := q.Execute(ctx)
+type Query struct {
+	// LedgerVersion is the version of the ledger info the results were
+	// verified against.
+	LedgerVersion uint64
+
+	libra    *LibraRPC
+	items    []*types.RequestItem
+	handlers []func(ledgerInfo *types.LedgerInfo, item *types.ResponseItem) error
+	err      error
+}
+
+type AccountStateResult struct {
+	// Account is nil when the account does not exist.
+	Account *models.AccountModel
+}
+
+type TransactionsResult struct {
+	Transactions []models.BlockModel
+}
+
+type AccountTransactionResult struct {
+	Transaction *models.AccountTransactionModel
+}
+
+type AccountEventsResult struct {
+	Events *models.AccountEventsModel
+}
+
+func (libra *LibraRPC) NewQuery() *Query {
+	return &Query{libra: libra}
+}
+
+func (q *Query) add(item *types.RequestItem, handler func(ledgerInfo *types.LedgerInfo, item *types.ResponseItem) error) {
+	q.items = append(q.items, item)
+	q.handlers = append(q.handlers, handler)
+}
+
+func (q *Query) address(address string) []byte {
+	addressBytes, err := HexToBytes(address)
+	if err != nil && q.err == nil {
+		q.err = err
+	}
+	return addressBytes
+}
+
+func (q *Query) AccountState(address string) *AccountStateResult {
+	result := &AccountStateResult{}
+	addressBytes := q.address(address)
+
+	q.add(q.libra.getAccountStateRequestMaker(addressBytes), func(ledgerInfo *types.LedgerInfo, item *types.ResponseItem) error {
+		resp := item.GetGetAccountStateResponse()
+		if resp == nil {
+			return fmt.Errorf("expected account state, got %T", item.ResponseItems)
+		}
+
+		account, err := q.libra.newAccountModel(addressBytes, ledgerInfo, resp.AccountStateWithProof)
+		result.Account = account
+		return err
+	})
+
+	return result
+}
+
+func (q *Query) Transactions(version uint64, limit uint64, fetchEvents bool) *TransactionsResult {
+	result := &TransactionsResult{}
+
+	q.add(q.libra.getTransactionsRequestMaker(version, limit, fetchEvents), func(ledgerInfo *types.LedgerInfo, item *types.ResponseItem) error {
+		resp := item.GetGetTransactionsResponse()
+		if resp == nil {
+			return fmt.Errorf("expected transactions, got %T", item.ResponseItems)
+		}
+
+		transactions, err := q.libra.newBlockModels(ledgerInfo, version, fetchEvents, resp)
+		if err != nil {
+			return err
+		}
+		result.Transactions = *transactions
+		return nil
+	})
+
+	return result
+}
+
+func (q *Query) AccountTransaction(address string, sequenceNumber uint64, fetchEvents bool) *AccountTransactionResult {
+	result := &AccountTransactionResult{}
+	addressBytes := q.address(address)
+
+	// the account state proves the current sequence number, see
+	// GetAccountTransactionBySequenceNumber
+	var state *types.AccountStateWithProof
+	q.add(q.libra.getAccountStateRequestMaker(addressBytes), func(ledgerInfo *types.LedgerInfo, item *types.ResponseItem) error {
+		resp := item.GetGetAccountStateResponse()
+		if resp == nil {
+			return fmt.Errorf("expected account state, got %T", item.ResponseItems)
+		}
+
+		state = resp.AccountStateWithProof
+		return nil
+	})
+
+	q.add(q.libra.getAccountTransactionBySequenceNumberRequestMaker(addressBytes, sequenceNumber, fetchEvents), func(ledgerInfo *types.LedgerInfo, item *types.ResponseItem) error {
+		resp := item.GetGetAccountTransactionBySequenceNumberResponse()
+		if resp == nil {
+			return fmt.Errorf("expected account transaction, got %T", item.ResponseItems)
+		}
+
+		transaction, err := q.libra.newAccountTransactionModel(addressBytes, sequenceNumber, fetchEvents, ledgerInfo, state, resp)
+		result.Transaction = transaction
+		return err
+	})
+
+	return result
+}
+
+func (q *Query) AccountEvents(address string, eventType string, start uint64, ascending bool, limit uint64) *AccountEventsResult {
+	result := &AccountEventsResult{}
+	addressBytes := q.address(address)
+
+	path, err := accountEventPath(eventType)
+	if err != nil && q.err == nil {
+		q.err = err
+	}
+
+	if limit > 50 {
+		limit = 50
+	}
+
+	// the account state proves the handle and its event count, see
+	// GetAccountEvents
+	var state *types.AccountStateWithProof
+	q.add(q.libra.getAccountStateRequestMaker(addressBytes), func(ledgerInfo *types.LedgerInfo, item *types.ResponseItem) error {
+		resp := item.GetGetAccountStateResponse()
+		if resp == nil {
+			return fmt.Errorf("expected account state, got %T", item.ResponseItems)
+		}
+
+		state = resp.AccountStateWithProof
+		return nil
+	})
+
+	q.add(q.libra.getEventsByEventAccessPathRequestMaker(addressBytes, path, start, ascending, limit), func(ledgerInfo *types.LedgerInfo, item *types.ResponseItem) error {
+		resp := item.GetGetEventsByEventAccessPathResponse()
+		if resp == nil {
+			return fmt.Errorf("expected events, got %T", item.ResponseItems)
+		}
+
+		events, err := q.libra.newAccountEventsModel(addressBytes, eventType, start, ascending, limit, ledgerInfo, state, resp)
+		result.Events = events
+		return err
+	})
+
+	return result
+}
+
+// Execute sends all items at once and fills in their results. It fails as a
+// whole if any item cannot be decoded or verified.
+func (q *Query) Execute(ctx context.Context) error {
+	if q.err != nil {
+		return q.err
+	}
+
+	r, err := q.libra.updateToLatestLedgerRequest(ctx, q.items)
+	if err != nil {
+		return err
+	}
+
+	ledgerInfo := r.LedgerInfoWithSigs.GetLedgerInfo()
+	if ledgerInfo == nil {
+		return errors.New("missing ledger info in response")
+	}
+
+	if len(r.ResponseItems) != len(q.items) {
+		return fmt.Errorf("got %d response items for %d requests", len(r.ResponseItems), len(q.items))
+	}
+
+	for i, item := range r.ResponseItems {
+		if err := q.handlers[i](ledgerInfo, item); err != nil {
+			return err
+		}
+	}
+
+	q.LedgerVersion = ledgerInfo.Version
+	return nil
+}
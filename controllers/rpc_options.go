@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"crypto/tls"
+	"os"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
+)
+
+const (
+	DefaultTimeout          = 10 * time.Second
+	DefaultKeepaliveTimeout = 10 * time.Second
+
+	// MinKeepaliveTime is the shortest ping interval a gRPC server accepts
+	// by default; pinging more often gets the connection closed with
+	// GOAWAY too_many_pings.
+	MinKeepaliveTime = 5 * time.Minute
+)
+
+type RPCOptions struct {
+	// Timeout bounds every single call to the node; zero leaves calls
+	// bounded by their context only.
+	Timeout time.Duration
+	// KeepaliveTime is how long the connection may stay idle before it is
+	// pinged, while calls are in flight; zero, the default, disables
+	// keepalive pings. Servers must permit it, see MinKeepaliveTime.
+	KeepaliveTime    time.Duration
+	KeepaliveTimeout time.Duration
+	TLS              bool
+	TLSServerName    string
+	// StorageAddress enables historical queries against a storage service,
+	// which only validators and full nodes expose.
+	StorageAddress string
+}
+
+func DefaultRPCOptions() RPCOptions {
+	return RPCOptions{
+		Timeout:          DefaultTimeout,
+		KeepaliveTimeout: DefaultKeepaliveTimeout,
+	}
+}
+
+// RPCOptionsFromEnv reads LIBRA_RPC_TIMEOUT, LIBRA_RPC_KEEPALIVE,
+// LIBRA_RPC_KEEPALIVE_TIMEOUT (durations such as "10s"), LIBRA_RPC_TLS and
+// LIBRA_RPC_TLS_SERVER_NAME and LIBRA_STORAGE_ADDRESS on top of the defaults.
+func RPCOptionsFromEnv() RPCOptions {
+	options := DefaultRPCOptions()
+
+	if d, err := time.ParseDuration(os.Getenv("LIBRA_RPC_TIMEOUT")); err == nil {
+		options.Timeout = d
+	}
+	if d, err := time.ParseDuration(os.Getenv("LIBRA_RPC_KEEPALIVE")); err == nil {
+		options.KeepaliveTime = d
+	}
+	if d, err := time.ParseDuration(os.Getenv("LIBRA_RPC_KEEPALIVE_TIMEOUT")); err == nil {
+		options.KeepaliveTimeout = d
+	}
+	options.TLS = os.Getenv("LIBRA_RPC_TLS") == "true"
+	options.TLSServerName = os.Getenv("LIBRA_RPC_TLS_SERVER_NAME")
+	options.StorageAddress = os.Getenv("LIBRA_STORAGE_ADDRESS")
+
+	return options
+}
+
+// AddressFromEnv returns LIBRA_RPC_ADDRESS, or nil to use DefaultAddress.
+func AddressFromEnv() *string {
+	if address := os.Getenv("LIBRA_RPC_ADDRESS"); address != "" {
+		return &address
+	}
+	return nil
+}
+
+func (options RPCOptions) DialOptions() []grpc.DialOption {
+	var opts []grpc.DialOption
+
+	if options.TLS {
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{ServerName: options.TLSServerName})))
+	} else {
+		opts = append(opts, grpc.WithInsecure())
+	}
+
+	if options.KeepaliveTime > 0 {
+		opts = append(opts, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                options.KeepaliveTime,
+			Timeout:             options.KeepaliveTimeout,
+			PermitWithoutStream: false,
+		}))
+	}
+
+	return opts
+}
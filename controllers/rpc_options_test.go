@@ -0,0 +1,29 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDefaultRPCOptionsDisableKeepalive(t *testing.T) {
+	options := DefaultRPCOptions()
+	if options.KeepaliveTime != 0 || options.Timeout != DefaultTimeout {
+		t.Errorf("got %+v", options)
+	}
+}
+
+func TestCallContext(t *testing.T) {
+	libra := LibraRPC{}
+	ctx, cancel := libra.callContext(context.Background())
+	defer cancel()
+	if _, ok := ctx.Deadline(); ok || ctx.Err() != nil {
+		t.Errorf("zero timeout set a deadline: %v", ctx.Err())
+	}
+
+	libra.Options = DefaultRPCOptions()
+	ctx, cancel = libra.callContext(context.Background())
+	defer cancel()
+	if _, ok := ctx.Deadline(); !ok {
+		t.Error("no deadline with the default timeout")
+	}
+}
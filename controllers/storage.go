@@ -0,0 +1,118 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"io.librablock.go/hasher"
+	"io.librablock.go/models"
+	"io.librablock.go/proto/storage"
+	"io.librablock.go/proto/types"
+	"io.librablock.go/verifier"
+)
+
+var ErrNoStorage = errors.New("no storage service configured")
+
+// GetAccountStateByVersion returns the state of address right after the
+// transaction at version executed. The state comes from the storage service
+// and is checked against the state root of that transaction, which in turn is
+// proven against the latest ledger info of the admission control node.
+func (libra *LibraRPC) GetAccountStateByVersion(ctx context.Context, address string, version uint64) (*models.AccountModel, error) {
+	if libra.storage == nil {
+		return nil, ErrNoStorage
+	}
+
+	addressBytes, err := HexToBytes(address)
+	if err != nil {
+		return nil, err
+	}
+
+	ledgerInfo, info, err := libra.getTransactionInfo(ctx, version)
+	if err != nil {
+		return nil, err
+	}
+
+	callCtx, cancel := libra.callContext(ctx)
+	defer cancel()
+
+	r, err := libra.storage.GetAccountStateWithProofByVersion(callCtx, &storage.GetAccountStateWithProofByVersionRequest{
+		Address: addressBytes,
+		Version: version,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	blob := r.GetAccountStateBlob().GetBlob()
+	err = verifier.VerifySparseMerkleElement(info.StateRootHash, hasher.AccountAddress(addressBytes), blob, r.SparseMerkleProof)
+	if err != nil {
+		return nil, err
+	}
+
+	if blob == nil {
+		return nil, nil
+	}
+
+	result, err := decodeAccountModel(addressBytes, blob)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Verified = libra.TrustedState != nil
+	result.Version = version
+	result.LedgerVersion = ledgerInfo.Version
+	result.StateRootHash = BytesToHex(info.StateRootHash)
+
+	return result, nil
+}
+
+// getTransactionInfo fetches the info of the transaction at version together
+// with the ledger info it has been verified against.
+func (libra *LibraRPC) getTransactionInfo(ctx context.Context, version uint64) (*types.LedgerInfo, *types.TransactionInfo, error) {
+	r, err := libra.updateToLatestLedgerRequest(ctx, []*types.RequestItem{libra.getTransactionsRequestMaker(version, 1, false)})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ledgerInfo := r.LedgerInfoWithSigs.GetLedgerInfo()
+	if ledgerInfo == nil {
+		return nil, nil, errors.New("missing ledger info in response")
+	}
+
+	for _, v := range r.ResponseItems {
+		switch val := v.ResponseItems.(type) {
+		case *types.ResponseItem_GetTransactionsResponse:
+			info, err := verifiedTransactionInfo(ledgerInfo, version, val.GetTransactionsResponse.TxnListWithProof)
+			return ledgerInfo, info, err
+		}
+	}
+
+	return nil, nil, errors.New("missing transactions in response")
+}
+
+// verifiedTransactionInfo proves the only transaction info of list against
+// ledgerInfo, whatever VerifyProofs says: its state root is all the storage
+// service's answer is checked against.
+func verifiedTransactionInfo(ledgerInfo *types.LedgerInfo, version uint64, list *types.TransactionListWithProof) (*types.TransactionInfo, error) {
+	if list == nil || (len(list.Infos) == 0 && len(list.Transactions) == 0) {
+		return nil, fmt.Errorf("version %d not found", version)
+	}
+
+	if len(list.Infos) != 1 || len(list.Transactions) != 1 {
+		return nil, verifier.Errorf("got %d transactions and %d infos for version %d", len(list.Transactions), len(list.Infos), version)
+	}
+	if list.FirstTransactionVersion == nil || list.FirstTransactionVersion.Value != version {
+		return nil, verifier.Errorf("transaction list does not start at requested version %d", version)
+	}
+
+	info := list.Infos[0]
+	if err := verifier.VerifyTransactionList(ledgerInfo, version, list.Infos, list.ProofOfFirstTransaction, list.ProofOfLastTransaction); err != nil {
+		return nil, err
+	}
+	if err := verifier.VerifySignedTransaction(info, list.Transactions[0]); err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
@@ -0,0 +1,49 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"io.librablock.go/proto/types"
+	"io.librablock.go/testutil"
+	"io.librablock.go/verifier"
+)
+
+func TestVerifiedTransactionInfo(t *testing.T) {
+	ac := testutil.PaymentLedger(t, 4)
+	ledgerInfo, resp := getTransactions(t, ac, 2, 1)
+
+	info, err := verifiedTransactionInfo(ledgerInfo, 2, resp.TxnListWithProof)
+	if err != nil || !proto.Equal(info, resp.TxnListWithProof.Infos[0]) {
+		t.Fatalf("got %v, %v", info, err)
+	}
+
+	tests := []struct {
+		name   string
+		tamper func(list *types.TransactionListWithProof)
+	}{
+		{"made up info without transactions", func(list *types.TransactionListWithProof) {
+			list.Transactions = nil
+			list.Infos[0].StateRootHash = make([]byte, len(list.Infos[0].StateRootHash))
+		}},
+		{"made up state root", func(list *types.TransactionListWithProof) {
+			list.Infos[0].StateRootHash = make([]byte, len(list.Infos[0].StateRootHash))
+		}},
+		{"other transaction", func(list *types.TransactionListWithProof) {
+			list.Transactions[0] = fakeTransaction(t, testutil.Alice, 2, testutil.Alice, 12)
+		}},
+		{"other version", func(list *types.TransactionListWithProof) {
+			list.FirstTransactionVersion.Value = 1
+		}},
+	}
+
+	for _, tt := range tests {
+		// the fake shares its state with the response, tamper with a copy
+		list := proto.Clone(resp.TxnListWithProof).(*types.TransactionListWithProof)
+		tt.tamper(list)
+
+		if _, err := verifiedTransactionInfo(ledgerInfo, 2, list); !verifier.IsVerificationError(err) {
+			t.Errorf("%s: got %v, want a verification error", tt.name, err)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"io.librablock.go/models"
+	"io.librablock.go/proto/admission_control"
+	"io.librablock.go/proto/mempool"
+	"io.librablock.go/proto/types"
+)
+
+const (
+	AdmissionControlErrorSource = "admission_control"
+	MempoolErrorSource          = "mempool"
+	VMErrorSource               = "vm"
+)
+
+func (libra *LibraRPC) SubmitTransaction(ctx context.Context, signedTxn *types.SignedTransaction) (*models.SubmitTransactionModel, error) {
+	ctx, cancel := libra.callContext(ctx)
+	defer cancel()
+
+	r, err := libra.client.SubmitTransaction(ctx, &admission_control.SubmitTransactionRequest{SignedTxn: signedTxn})
+	if err != nil {
+		return nil, err
+	}
+
+	result := models.SubmitTransactionModel{}
+	result.ValidatorID = BytesToHex(r.ValidatorId)
+
+	switch status := r.Status.(type) {
+	case *admission_control.SubmitTransactionResponse_AcStatus:
+		result.Accepted = status.AcStatus.Code == admission_control.AdmissionControlStatusCode_Accepted
+		if !result.Accepted {
+			result.Error = &models.SubmitErrorModel{
+				Source:  AdmissionControlErrorSource,
+				Code:    status.AcStatus.Code.String(),
+				Message: status.AcStatus.Message,
+			}
+		}
+	case *admission_control.SubmitTransactionResponse_MempoolStatus:
+		result.Accepted = status.MempoolStatus.Code == mempool.MempoolAddTransactionStatusCode_Valid
+		if !result.Accepted {
+			result.Error = &models.SubmitErrorModel{
+				Source:  MempoolErrorSource,
+				Code:    status.MempoolStatus.Code.String(),
+				Message: status.MempoolStatus.Message,
+			}
+		}
+	case *admission_control.SubmitTransactionResponse_VmStatus:
+		// admission control only answers with a VM status when the VM
+		// rejected the transaction
+		result.Error = vmStatusToError(status.VmStatus)
+	default:
+		return nil, fmt.Errorf("unknown submit transaction status %T", r.Status)
+	}
+
+	return &result, nil
+}
+
+func vmStatusToError(status *types.VMStatus) *models.SubmitErrorModel {
+	result := models.SubmitErrorModel{
+		Source:  VMErrorSource,
+		Code:    "Unknown",
+		Message: "rejected by the VM without a known error",
+	}
+
+	switch e := status.GetErrorType().(type) {
+	case *types.VMStatus_Validation:
+		result.Type = "validation"
+		result.Code = e.Validation.Code.String()
+		result.Message = e.Validation.Message
+	case *types.VMStatus_Verification:
+		result.Type = "verification"
+		var messages []string
+		for _, s := range e.Verification.StatusList {
+			if result.Code == "" {
+				result.Code = s.ErrorKind.String()
+			}
+			messages = append(messages, fmt.Sprintf("%s %d: %s: %s", s.StatusKind.String(), s.ModuleIdx, s.ErrorKind.String(), s.Message))
+		}
+		result.Message = strings.Join(messages, "; ")
+	case *types.VMStatus_InvariantViolation:
+		result.Type = "invariant_violation"
+		result.Code = e.InvariantViolation.String()
+	case *types.VMStatus_Deserialization:
+		result.Type = "deserialization"
+		result.Code = e.Deserialization.String()
+	case *types.VMStatus_Execution:
+		result.Type = "execution"
+		switch x := e.Execution.GetExecutionStatus().(type) {
+		case *types.ExecutionStatus_RuntimeStatus:
+			result.Code = x.RuntimeStatus.String()
+		case *types.ExecutionStatus_Aborted:
+			result.Code = "Aborted"
+			result.Message = fmt.Sprintf("aborted with code %d", x.Aborted.AbortedErrorCode)
+		case *types.ExecutionStatus_ArithmeticError:
+			result.Code = x.ArithmeticError.ErrorCode.String()
+		case *types.ExecutionStatus_ReferenceError:
+			result.Code = x.ReferenceError.ErrorCode.String()
+		}
+	}
+
+	return &result
+}
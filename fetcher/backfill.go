@@ -0,0 +1,187 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"io.librablock.go/controllers"
+	"io.librablock.go/models"
+	"io.librablock.go/utils"
+)
+
+const (
+	DefaultWindow      = 1000
+	DefaultParallelism = 4
+	DefaultMaxRetry    = 5
+)
+
+type BackfillOptions struct {
+	Window      uint64
+	Parallelism int
+	MaxRetry    int
+}
+
+type window struct {
+	start  uint64
+	limit  uint64
+	blocks []models.BlockModel
+	err    error
+}
+
+func (o BackfillOptions) normalize() BackfillOptions {
+	if o.Window == 0 {
+		o.Window = DefaultWindow
+	}
+	if o.Parallelism <= 0 {
+		o.Parallelism = DefaultParallelism
+	}
+	if o.MaxRetry <= 0 {
+		o.MaxRetry = DefaultMaxRetry
+	}
+	return o
+}
+
+// Backfill fetches versions [from, to] in windows of opts.Window versions using
+// opts.Parallelism concurrent workers. Windows are committed strictly in
+// version order, so the latest stored version never skips over a gap. It
+// returns the last version that was committed.
+func Backfill(ctx context.Context, rpc *controllers.LibraRPC, db utils.Store, from uint64, to uint64, opts BackfillOptions) (uint64, error) {
+	opts = opts.normalize()
+	committed := from - 1
+
+	if from > to {
+		return committed, nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan window)
+	results := make(chan window)
+
+	// limit how many windows may be fetched ahead of the commit point
+	slots := make(chan struct{}, opts.Parallelism*2)
+
+	go func() {
+		defer close(jobs)
+		for start := from; start <= to; start += opts.Window {
+			limit := opts.Window
+			if to-start+1 < limit {
+				limit = to - start + 1
+			}
+
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+
+			select {
+			case jobs <- window{start: start, limit: limit}:
+			case <-ctx.Done():
+				return
+			}
+
+			if start+opts.Window < start {
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < opts.Parallelism; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for w := range jobs {
+				w.blocks, w.err = fetchWindow(ctx, rpc, w.start, w.limit, opts.MaxRetry)
+				select {
+				case results <- w:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	pending := make(map[uint64]window)
+	next := from
+
+	for w := range results {
+		if w.err != nil {
+			return committed, w.err
+		}
+		pending[w.start] = w
+
+		for {
+			ready, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+
+			if err := db.SaveBlocks(ready.blocks); err != nil {
+				return committed, err
+			}
+			committed = ready.start + ready.limit - 1
+			fmt.Printf("Success Fetch Version: %d - %d\n", ready.start, committed)
+
+			next = ready.start + ready.limit
+			<-slots
+		}
+
+		if committed == to {
+			break
+		}
+	}
+
+	if committed != to {
+		if ctx.Err() != nil {
+			return committed, ctx.Err()
+		}
+		return committed, errors.New("backfill stopped before reaching target version")
+	}
+
+	return committed, nil
+}
+
+func fetchWindow(ctx context.Context, rpc *controllers.LibraRPC, start uint64, limit uint64, maxRetry int) ([]models.BlockModel, error) {
+	var blocks []models.BlockModel
+	errCnt := 0
+
+	for uint64(len(blocks)) < limit {
+		version := start + uint64(len(blocks))
+		r, err := rpc.GetTransactions(ctx, version, limit-uint64(len(blocks)), true)
+
+		if err == nil && len(*r) == 0 {
+			err = fmt.Errorf("node returned no transactions at version %d", version)
+		}
+
+		if err != nil {
+			errCnt += 1
+			if errCnt > maxRetry {
+				return nil, err
+			}
+
+			select {
+			case <-time.After(time.Duration(errCnt) * 500 * time.Millisecond):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+			continue
+		}
+
+		blocks = append(blocks, *r...)
+		errCnt = 0
+	}
+
+	return blocks, nil
+}
@@ -0,0 +1,89 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"io.librablock.go/controllers"
+	"io.librablock.go/utils"
+)
+
+const maxErrors = 10
+
+var ErrMaxRetry = errors.New("block fetcher failed too many times in a row")
+
+func haveARest() {
+	time.Sleep(250 * time.Microsecond)
+}
+
+// Run keeps db in sync with the node until ctx is cancelled, starting after
+// the checkpoint of db. Gaps larger than opts.Window are fetched with
+// Backfill. It gives up with ErrMaxRetry after too many consecutive errors.
+func Run(ctx context.Context, rpc *controllers.LibraRPC, db utils.Store, opts BackfillOptions) error {
+	opts = opts.normalize()
+	errCnt := 0
+
+	for ctx.Err() == nil {
+		if errCnt > maxErrors {
+			return ErrMaxRetry
+		}
+
+		latestVersion, err := rpc.GetLatestVersion(ctx)
+		if err != nil {
+			errCnt += 1
+			haveARest()
+			continue
+		}
+
+		dbLatestVersion, err := db.GetCheckpoint()
+		if err != nil {
+			fmt.Println(err.Error())
+			errCnt += 1
+			haveARest()
+			continue
+		}
+
+		if latestVersion <= dbLatestVersion {
+			haveARest()
+			errCnt = 0
+			continue
+		}
+
+		limit := latestVersion - dbLatestVersion
+
+		if limit > opts.Window {
+			_, err := Backfill(ctx, rpc, db, dbLatestVersion+1, latestVersion, opts)
+			if err != nil {
+				fmt.Println(err.Error())
+				errCnt += 1
+				haveARest()
+				continue
+			}
+
+			errCnt = 0
+			continue
+		}
+
+		r, err := rpc.GetTransactions(ctx, dbLatestVersion+1, limit, true)
+		if err != nil {
+			errCnt += 1
+			haveARest()
+			continue
+		}
+
+		if err := db.SaveBlocks(*r); err != nil {
+			fmt.Println(err.Error())
+			errCnt += 1
+			haveARest()
+			continue
+		}
+		for _, v := range *r {
+			fmt.Printf("Success Fetch Version: %d\n", v.Version)
+		}
+		errCnt = 0
+	}
+
+	return nil
+}
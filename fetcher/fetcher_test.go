@@ -0,0 +1,115 @@
+package fetcher
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"io.librablock.go/controllers"
+	"io.librablock.go/testutil"
+	"io.librablock.go/testutil/fakerpc"
+	"io.librablock.go/utils"
+)
+
+func waitForCheckpoint(t *testing.T, db utils.Store, version uint64) {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		checkpoint, err := db.GetCheckpoint()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if checkpoint >= version {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("checkpoint did not reach %d", version)
+}
+
+func checkStored(t *testing.T, db utils.Store, from uint64, to uint64) {
+	for version := from; version <= to; version++ {
+		block, err := db.GetVersion(version)
+		if err != nil {
+			t.Fatalf("version %d: %v", version, err)
+		}
+		events, err := db.GetEventsByVersion(version)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if block.Source != controllers.BytesToHex(testutil.Alice) || len(events) != 2 {
+			t.Errorf("version %d: got %+v with %d events", version, block, len(events))
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	ac := testutil.PaymentLedger(t, 6)
+	ac.Validators = testutil.FakeValidators(t, 4)
+	rpc, stop := fakerpc.Dial(t, ac)
+	defer stop()
+	db := utils.NewMemoryStore()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		// a window smaller than the gap takes the backfill path first
+		done <- Run(ctx, rpc, db, BackfillOptions{Window: 2, Parallelism: 2})
+	}()
+
+	waitForCheckpoint(t, db, 5)
+
+	// then it follows new versions
+	if err := ac.CommitPayments(testutil.Alice, testutil.Bob, 1); err != nil {
+		t.Fatal(err)
+	}
+	waitForCheckpoint(t, db, 6)
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	// the fetcher starts after the genesis version
+	checkStored(t, db, 1, 6)
+}
+
+func TestBackfill(t *testing.T) {
+	ac := testutil.PaymentLedger(t, 8)
+	ac.Validators = testutil.FakeValidators(t, 4)
+	rpc, stop := fakerpc.Dial(t, ac)
+	defer stop()
+	db := utils.NewMemoryStore()
+
+	committed, err := Backfill(context.Background(), rpc, db, 1, 7, BackfillOptions{Window: 2, Parallelism: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if committed != 7 {
+		t.Errorf("committed %d, want 7", committed)
+	}
+
+	checkStored(t, db, 1, 7)
+	if checkpoint, _ := db.GetCheckpoint(); checkpoint != 7 {
+		t.Errorf("checkpoint %d, want 7", checkpoint)
+	}
+}
+
+func TestRunGivesUpOnUnverifiedNode(t *testing.T) {
+	ac := testutil.PaymentLedger(t, 3)
+	ac.Validators = testutil.FakeValidators(t, 4)
+	rpc, stop := fakerpc.Dial(t, ac)
+	defer stop()
+	db := utils.NewMemoryStore()
+
+	// ledger infos signed by validators the fetcher does not trust
+	untrusted := testutil.NewFakeAdmissionControl()
+	untrusted.Validators = testutil.FakeValidators(t, 4)
+	rpc.TrustedState = untrusted.TrustedState()
+
+	if err := Run(context.Background(), rpc, db, BackfillOptions{}); err != ErrMaxRetry {
+		t.Fatalf("got %v, want ErrMaxRetry", err)
+	}
+	if latest, _ := db.GetLatestVersion(); latest != 0 {
+		t.Errorf("stored up to version %d", latest)
+	}
+}
@@ -0,0 +1,108 @@
+package hasher
+
+import (
+	"golang.org/x/crypto/sha3"
+	"io.librablock.go/lcs"
+	"io.librablock.go/proto/types"
+)
+
+const (
+	HashLength = 32
+	hashSuffix = "@@$$LIBRA$$@@"
+
+	TransactionAccumulatorSalt = "TransactionAccumulator"
+	EventAccumulatorSalt       = "EventAccumulator"
+	TransactionInfoSalt        = "TransactionInfo"
+	LedgerInfoSalt             = "LedgerInfo"
+	RawTransactionSalt         = "RawTransaction"
+	SignedTransactionSalt      = "SignedTransaction"
+	ContractEventSalt          = "ContractEvent"
+	AccountAddressSalt         = "AccountAddress"
+	AccountStateBlobSalt       = "AccountStateBlob"
+	SparseMerkleInternalSalt   = "SparseMerkleInternal"
+	SparseMerkleLeafNodeSalt   = "SparseMerkleLeafNode"
+)
+
+var (
+	AccumulatorPlaceholderHash  = placeholder("ACCUMULATOR_PLACEHOLDER_HASH")
+	SparseMerklePlaceholderHash = placeholder("SPARSE_MERKLE_PLACEHOLDER_HASH")
+)
+
+func placeholder(name string) []byte {
+	hash := make([]byte, HashLength)
+	copy(hash, name)
+	return hash
+}
+
+// Sum hashes data the way Libra's CryptoHasher does: SHA3-256 seeded with the
+// SHA3-256 of the type name plus the Libra hash suffix.
+func Sum(salt string, data ...[]byte) []byte {
+	state := sha3.New256()
+
+	prefix := sha3.Sum256([]byte(salt + hashSuffix))
+	state.Write(prefix[:])
+
+	for _, d := range data {
+		state.Write(d)
+	}
+
+	return state.Sum(nil)
+}
+
+func TransactionInfo(signedTransactionHash []byte, stateRootHash []byte, eventRootHash []byte, gasUsed uint64) []byte {
+	return Sum(TransactionInfoSalt, lcs.NewEncoder().
+		EncodeFixedBytes(signedTransactionHash).
+		EncodeFixedBytes(stateRootHash).
+		EncodeFixedBytes(eventRootHash).
+		EncodeU64(gasUsed).
+		Result())
+}
+
+func TransactionAccumulatorNode(left []byte, right []byte) []byte {
+	return Sum(TransactionAccumulatorSalt, left, right)
+}
+
+func EventAccumulatorNode(left []byte, right []byte) []byte {
+	return Sum(EventAccumulatorSalt, left, right)
+}
+
+func LedgerInfo(ledgerInfo *types.LedgerInfo) []byte {
+	return Sum(LedgerInfoSalt, lcs.NewEncoder().
+		EncodeU64(ledgerInfo.Version).
+		EncodeFixedBytes(ledgerInfo.TransactionAccumulatorHash).
+		EncodeFixedBytes(ledgerInfo.ConsensusDataHash).
+		EncodeFixedBytes(ledgerInfo.ConsensusBlockId).
+		EncodeU64(ledgerInfo.EpochNum).
+		EncodeU64(ledgerInfo.TimestampUsecs).
+		Result())
+}
+
+// SignedTransaction hashes the protobuf serialization of a signed
+// transaction, the message whose hash a transaction info commits to.
+func SignedTransaction(signedTxnBytes []byte) []byte {
+	return Sum(SignedTransactionSalt, signedTxnBytes)
+}
+
+func ContractEvent(event *types.Event) []byte {
+	return Sum(ContractEventSalt, lcs.NewEncoder().
+		EncodeBytes(event.Key).
+		EncodeU64(event.SequenceNumber).
+		EncodeBytes(event.EventData).
+		Result())
+}
+
+func AccountAddress(address []byte) []byte {
+	return Sum(AccountAddressSalt, address)
+}
+
+func AccountStateBlob(blob []byte) []byte {
+	return Sum(AccountStateBlobSalt, blob)
+}
+
+func SparseMerkleLeafNode(key []byte, valueHash []byte) []byte {
+	return Sum(SparseMerkleLeafNodeSalt, key, valueHash)
+}
+
+func SparseMerkleInternalNode(left []byte, right []byte) []byte {
+	return Sum(SparseMerkleInternalSalt, left, right)
+}
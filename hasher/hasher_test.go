@@ -0,0 +1,51 @@
+package hasher
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"io.librablock.go/proto/types"
+)
+
+// The expected hashes below were computed outside of this package, with
+// Python's hashlib.sha3_256 following the same salted scheme. They are not
+// recorded from a Libra node.
+func TestHashVectors(t *testing.T) {
+	fill := func(b byte) []byte { return bytes.Repeat([]byte{b}, HashLength) }
+
+	tests := []struct {
+		name string
+		got  []byte
+		want string
+	}{
+		{"salt only", Sum(TransactionAccumulatorSalt), "157936e7139d67bf68f72173bb5511e267d31e8721189adde3133fb81a9852fe"},
+		{"accumulator node", TransactionAccumulatorNode(fill(1), fill(2)), "13bc1b026bc21dd59c56fd8abd13ae48cab7849a48c305a0e90352ce9156c80e"},
+		{"contract event", ContractEvent(&types.Event{Key: fill(0xaa), SequenceNumber: 7, EventData: []byte("data")}), "ebd04e7058ec80efbee1fde1552b17e545cb0ec32ee1300b40cd3424ecd73968"},
+		{"ledger info", LedgerInfo(&types.LedgerInfo{
+			Version:                    42,
+			TransactionAccumulatorHash: fill(3),
+			ConsensusDataHash:          fill(4),
+			ConsensusBlockId:           fill(5),
+			EpochNum:                   1,
+			TimestampUsecs:             1000,
+		}), "0c6d52dd670b55e16844e713ef4b0df1ab8da1a452acf8debca76171c3bb7192"},
+		{"transaction info", TransactionInfo(fill(6), fill(7), fill(8), 9), "892d42a396c7c90af276c1885727765df4dfa8a5047a06da2a9280a9f677e98c"},
+		{"account address", AccountAddress(fill(0xaa)), "ee9fcf07fe2d1a732cf6b8ce16c01c7c8f4e0795ef0dbf6e2243a6fe128a24e8"},
+	}
+
+	for _, tt := range tests {
+		if got := hex.EncodeToString(tt.got); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPlaceholders(t *testing.T) {
+	if string(bytes.TrimRight(AccumulatorPlaceholderHash, "\x00")) != "ACCUMULATOR_PLACEHOLDER_HASH" || len(AccumulatorPlaceholderHash) != HashLength {
+		t.Errorf("accumulator placeholder %x", AccumulatorPlaceholderHash)
+	}
+	if string(bytes.TrimRight(SparseMerklePlaceholderHash, "\x00")) != "SPARSE_MERKLE_PLACEHOLDER_HASH" || len(SparseMerklePlaceholderHash) != HashLength {
+		t.Errorf("sparse merkle placeholder %x", SparseMerklePlaceholderHash)
+	}
+}
@@ -0,0 +1,47 @@
+package keys
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"golang.org/x/crypto/ed25519"
+	"golang.org/x/crypto/sha3"
+)
+
+type Key struct {
+	PrivateKey ed25519.PrivateKey
+	PublicKey  ed25519.PublicKey
+}
+
+func GenerateKey() (*Key, error) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Key{PrivateKey: private, PublicKey: public}, nil
+}
+
+func NewKeyFromSeed(seed []byte) *Key {
+	private := ed25519.NewKeyFromSeed(seed)
+	return &Key{PrivateKey: private, PublicKey: private.Public().(ed25519.PublicKey)}
+}
+
+// AuthenticationKey is the SHA3-256 of the public key. A fresh account's
+// address is its authentication key.
+func AuthenticationKey(publicKey []byte) []byte {
+	sum := sha3.Sum256(publicKey)
+	return sum[:]
+}
+
+func (key *Key) AuthenticationKey() []byte {
+	return AuthenticationKey(key.PublicKey)
+}
+
+func (key *Key) Address() []byte {
+	return key.AuthenticationKey()
+}
+
+func (key *Key) AddressHex() string {
+	return hex.EncodeToString(key.Address())
+}
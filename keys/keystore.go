@@ -0,0 +1,275 @@
+package keys
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/crypto/ed25519"
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	ScryptN = 1 << 18
+	ScryptR = 8
+	ScryptP = 1
+
+	// bounds on the scrypt parameters read back from key files, so that an
+	// edited file cannot make decryption take minutes or gigabytes
+	maxScryptN      = 1 << 20
+	maxScryptR      = 32
+	maxScryptP      = 16
+	maxScryptMemory = 1 << 30
+
+	keyFileSuffix = ".json"
+	addressLength = 32
+)
+
+var (
+	ErrWrongPassword = errors.New("keystore: wrong password")
+	ErrEmptyPassword = errors.New("keystore: empty password")
+)
+
+type cryptoParams struct {
+	KDF        string `json:"kdf"`
+	N          int    `json:"n"`
+	R          int    `json:"r"`
+	P          int    `json:"p"`
+	Salt       string `json:"salt"`
+	Nonce      string `json:"nonce"`
+	Ciphertext string `json:"ciphertext"`
+}
+
+type KeyFile struct {
+	Address   string       `json:"address"`
+	PublicKey string       `json:"public_key"`
+	Crypto    cryptoParams `json:"crypto"`
+}
+
+// KeyStore keeps one scrypt and secretbox encrypted key file per account in
+// a directory, named after the account address.
+type KeyStore struct {
+	Dir string
+	N   int
+}
+
+func NewKeyStore(dir string) *KeyStore {
+	return &KeyStore{Dir: dir, N: ScryptN}
+}
+
+func (ks *KeyStore) path(address string) string {
+	return filepath.Join(ks.Dir, address+keyFileSuffix)
+}
+
+// checkAddress accepts hex encoded account addresses only, which keeps the
+// key file path inside the store directory.
+func checkAddress(address string) error {
+	data, err := hex.DecodeString(address)
+	if err != nil || len(data) != addressLength {
+		return fmt.Errorf("keystore: bad address %q", address)
+	}
+	return nil
+}
+
+func (ks *KeyStore) Create(password string) (*Key, error) {
+	key, err := GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	return key, ks.Store(key, password)
+}
+
+func (ks *KeyStore) Store(key *Key, password string) error {
+	if password == "" {
+		return ErrEmptyPassword
+	}
+
+	file, err := encryptKey(key, password, ks.N)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(ks.Dir, 0700); err != nil {
+		return err
+	}
+
+	path := ks.path(file.Address)
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("keystore: %s already exists", path)
+	}
+
+	return ioutil.WriteFile(path, data, 0600)
+}
+
+// List returns the key files in the store ordered by address, without
+// decrypting them.
+func (ks *KeyStore) List() ([]KeyFile, error) {
+	entries, err := ioutil.ReadDir(ks.Dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var files []KeyFile
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), keyFileSuffix) {
+			continue
+		}
+
+		file, err := readKeyFile(filepath.Join(ks.Dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, *file)
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Address < files[j].Address
+	})
+
+	return files, nil
+}
+
+func (ks *KeyStore) Load(address string, password string) (*Key, error) {
+	address = strings.ToLower(address)
+	if err := checkAddress(address); err != nil {
+		return nil, err
+	}
+
+	file, err := readKeyFile(ks.path(address))
+	if err != nil {
+		return nil, err
+	}
+
+	return decryptKey(file, password)
+}
+
+func readKeyFile(path string) (*KeyFile, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	file := KeyFile{}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("keystore: %s: %v", path, err)
+	}
+
+	return &file, nil
+}
+
+func checkScryptParams(n int, r int, p int) error {
+	if n < 2 || n > maxScryptN || n&(n-1) != 0 {
+		return fmt.Errorf("keystore: scrypt n %d is not a power of two up to %d", n, maxScryptN)
+	}
+	if r < 1 || r > maxScryptR || p < 1 || p > maxScryptP {
+		return fmt.Errorf("keystore: scrypt r %d or p %d out of range", r, p)
+	}
+	if 128*n*r > maxScryptMemory {
+		return fmt.Errorf("keystore: scrypt n %d and r %d need more than %d bytes", n, r, maxScryptMemory)
+	}
+	return nil
+}
+
+func deriveKey(password string, salt []byte, n int, r int, p int) (*[32]byte, error) {
+	if err := checkScryptParams(n, r, p); err != nil {
+		return nil, err
+	}
+
+	derived, err := scrypt.Key([]byte(password), salt, n, r, p, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	var secret [32]byte
+	copy(secret[:], derived)
+	return &secret, nil
+}
+
+func encryptKey(key *Key, password string, n int) (*KeyFile, error) {
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	var nonce [24]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return nil, err
+	}
+
+	secret, err := deriveKey(password, salt, n, ScryptR, ScryptP)
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext := secretbox.Seal(nil, key.PrivateKey.Seed(), &nonce, secret)
+
+	return &KeyFile{
+		Address:   key.AddressHex(),
+		PublicKey: hex.EncodeToString(key.PublicKey),
+		Crypto: cryptoParams{
+			KDF:        "scrypt",
+			N:          n,
+			R:          ScryptR,
+			P:          ScryptP,
+			Salt:       hex.EncodeToString(salt),
+			Nonce:      hex.EncodeToString(nonce[:]),
+			Ciphertext: hex.EncodeToString(ciphertext),
+		},
+	}, nil
+}
+
+func decryptKey(file *KeyFile, password string) (*Key, error) {
+	if file.Crypto.KDF != "scrypt" {
+		return nil, fmt.Errorf("keystore: unsupported kdf %s", file.Crypto.KDF)
+	}
+
+	salt, err := hex.DecodeString(file.Crypto.Salt)
+	if err != nil {
+		return nil, err
+	}
+	nonceBytes, err := hex.DecodeString(file.Crypto.Nonce)
+	if err != nil || len(nonceBytes) != 24 {
+		return nil, errors.New("keystore: bad nonce")
+	}
+	ciphertext, err := hex.DecodeString(file.Crypto.Ciphertext)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := deriveKey(password, salt, file.Crypto.N, file.Crypto.R, file.Crypto.P)
+	if err != nil {
+		return nil, err
+	}
+
+	var nonce [24]byte
+	copy(nonce[:], nonceBytes)
+
+	seed, ok := secretbox.Open(nil, ciphertext, &nonce, secret)
+	if !ok || len(seed) != ed25519.SeedSize {
+		return nil, ErrWrongPassword
+	}
+
+	key := NewKeyFromSeed(seed)
+	if key.AddressHex() != file.Address {
+		return nil, errors.New("keystore: key does not match its address")
+	}
+
+	return key, nil
+}
@@ -0,0 +1,118 @@
+package keys
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestKeyStore(t *testing.T) (*KeyStore, func()) {
+	dir, err := ioutil.TempDir("", "keystore")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a cheap scrypt keeps the tests fast
+	ks := NewKeyStore(dir)
+	ks.N = 1 << 10
+	return ks, func() { os.RemoveAll(dir) }
+}
+
+func TestKeyStoreRoundTrip(t *testing.T) {
+	ks, cleanup := newTestKeyStore(t)
+	defer cleanup()
+
+	key, err := ks.Create("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := ks.Load(key.AddressHex(), "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !loaded.PrivateKey.Equal(key.PrivateKey) {
+		t.Error("loaded a different key")
+	}
+
+	if _, err := ks.Load(key.AddressHex(), "wrong"); err != ErrWrongPassword {
+		t.Errorf("got %v, want ErrWrongPassword", err)
+	}
+
+	files, err := ks.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].Address != key.AddressHex() {
+		t.Errorf("got %+v", files)
+	}
+}
+
+func TestKeyStoreRejectsEmptyPassword(t *testing.T) {
+	ks, cleanup := newTestKeyStore(t)
+	defer cleanup()
+
+	if _, err := ks.Create(""); err != ErrEmptyPassword {
+		t.Errorf("got %v, want ErrEmptyPassword", err)
+	}
+}
+
+func TestKeyStoreLoadRejectsBadAddress(t *testing.T) {
+	ks, cleanup := newTestKeyStore(t)
+	defer cleanup()
+
+	for _, address := range []string{"", "../secret", "../../etc/passwd", "abcd", strings.Repeat("g", 64)} {
+		if _, err := ks.Load(address, "secret"); err == nil || os.IsNotExist(err) {
+			t.Errorf("%q: got %v, want a bad address error", address, err)
+		}
+	}
+}
+
+func TestKeyStoreBoundsScryptParams(t *testing.T) {
+	ks, cleanup := newTestKeyStore(t)
+	defer cleanup()
+
+	key, err := ks.Create("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(ks.Dir, key.AddressHex()+keyFileSuffix)
+
+	original, err := readKeyFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		n, r, p int
+	}{
+		{"huge n", 1 << 30, 8, 1},
+		{"n not a power of two", 1000, 8, 1},
+		{"zero n", 0, 8, 1},
+		{"huge r", 1 << 10, 1 << 20, 1},
+		{"huge p", 1 << 10, 8, 1 << 20},
+		{"zero p", 1 << 10, 8, 0},
+		{"too much memory", 1 << 20, 16, 1},
+	}
+
+	for _, tt := range tests {
+		file := *original
+		file.Crypto.N, file.Crypto.R, file.Crypto.P = tt.n, tt.r, tt.p
+
+		data, err := json.Marshal(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, data, 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := ks.Load(key.AddressHex(), "secret"); err == nil || err == ErrWrongPassword {
+			t.Errorf("%s: got %v, want a parameter error", tt.name, err)
+		}
+	}
+}
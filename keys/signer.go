@@ -0,0 +1,100 @@
+package keys
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/ed25519"
+	"io.librablock.go/proto/secret_service"
+)
+
+// Signer signs transaction hashes on behalf of one account key. The local
+// signer holds the key in process, RemoteSigner leaves it in a SecretService.
+type Signer interface {
+	PublicKey(ctx context.Context) ([]byte, error)
+	Sign(ctx context.Context, hash []byte) ([]byte, error)
+}
+
+type localSigner struct {
+	key *Key
+}
+
+func (s localSigner) PublicKey(ctx context.Context) ([]byte, error) {
+	return s.key.PublicKey, nil
+}
+
+func (s localSigner) Sign(ctx context.Context, hash []byte) ([]byte, error) {
+	return ed25519.Sign(s.key.PrivateKey, hash), nil
+}
+
+func NewLocalSigner(key *Key) Signer {
+	return localSigner{key: key}
+}
+
+type RemoteSigner struct {
+	client secret_service.SecretServiceClient
+	keyID  []byte
+}
+
+func NewRemoteSigner(client secret_service.SecretServiceClient, keyID []byte) *RemoteSigner {
+	return &RemoteSigner{client: client, keyID: keyID}
+}
+
+// GenerateRemoteSigner asks the SecretService for a new ed25519 key and
+// returns a signer for it.
+func GenerateRemoteSigner(ctx context.Context, client secret_service.SecretServiceClient) (*RemoteSigner, error) {
+	r, err := client.GenerateKey(ctx, &secret_service.GenerateKeyRequest{Spec: secret_service.KeyType_Ed25519})
+	if err != nil {
+		return nil, err
+	}
+	if r.Code != secret_service.ErrorCode_Success {
+		return nil, fmt.Errorf("secret service: generate key: %s", r.Code.String())
+	}
+
+	return NewRemoteSigner(client, r.KeyId), nil
+}
+
+func (s *RemoteSigner) KeyID() []byte {
+	return s.keyID
+}
+
+func (s *RemoteSigner) PublicKey(ctx context.Context) ([]byte, error) {
+	r, err := s.client.GetPublicKey(ctx, &secret_service.PublicKeyRequest{KeyId: s.keyID})
+	if err != nil {
+		return nil, err
+	}
+	if r.Code != secret_service.ErrorCode_Success {
+		return nil, fmt.Errorf("secret service: get public key: %s", r.Code.String())
+	}
+	if len(r.PublicKey) != ed25519.PublicKeySize {
+		return nil, errors.New("secret service: bad public key length")
+	}
+
+	return r.PublicKey, nil
+}
+
+// Sign has the SecretService sign hash, and checks the signature against the
+// public key of the signer before handing it out.
+func (s *RemoteSigner) Sign(ctx context.Context, hash []byte) ([]byte, error) {
+	publicKey, err := s.PublicKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := s.client.Sign(ctx, &secret_service.SignRequest{KeyId: s.keyID, MessageHash: hash})
+	if err != nil {
+		return nil, err
+	}
+	if r.Code != secret_service.ErrorCode_Success {
+		return nil, fmt.Errorf("secret service: sign: %s", r.Code.String())
+	}
+	if len(r.Signature) != ed25519.SignatureSize {
+		return nil, errors.New("secret service: bad signature length")
+	}
+	if !ed25519.Verify(publicKey, hash, r.Signature) {
+		return nil, errors.New("secret service: signature does not match the public key")
+	}
+
+	return r.Signature, nil
+}
@@ -0,0 +1,130 @@
+package keys_test
+
+import (
+	"context"
+	"testing"
+
+	"golang.org/x/crypto/ed25519"
+	"google.golang.org/grpc"
+
+	"io.librablock.go/keys"
+	"io.librablock.go/proto/secret_service"
+	"io.librablock.go/testutil"
+)
+
+func serveSecretService(t *testing.T) (secret_service.SecretServiceClient, *testutil.FakeSecretService, func()) {
+	fake := testutil.NewFakeSecretService()
+
+	address, stop, err := testutil.Serve(func(s *grpc.Server) {
+		secret_service.RegisterSecretServiceServer(s, fake)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conn, err := grpc.Dial(address, grpc.WithInsecure())
+	if err != nil {
+		stop()
+		t.Fatal(err)
+	}
+
+	return secret_service.NewSecretServiceClient(conn), fake, func() {
+		conn.Close()
+		stop()
+	}
+}
+
+// tamperingClient flips a bit of every signature coming from the service.
+type tamperingClient struct {
+	secret_service.SecretServiceClient
+}
+
+func (c tamperingClient) Sign(ctx context.Context, req *secret_service.SignRequest, opts ...grpc.CallOption) (*secret_service.SignResponse, error) {
+	r, err := c.SecretServiceClient.Sign(ctx, req, opts...)
+	if err == nil && len(r.Signature) > 0 {
+		r.Signature[0] ^= 1
+	}
+	return r, err
+}
+
+func TestRemoteSignerRoundTrip(t *testing.T) {
+	client, _, stop := serveSecretService(t)
+	defer stop()
+
+	ctx := context.Background()
+	signer, err := keys.GenerateRemoteSigner(ctx, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	publicKey, err := signer.PublicKey(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hash := make([]byte, 32)
+	signature, err := signer.Sign(ctx, hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ed25519.Verify(publicKey, hash, signature) {
+		t.Error("signature does not verify")
+	}
+}
+
+func TestRemoteSignerMatchesLocalSigner(t *testing.T) {
+	client, fake, stop := serveSecretService(t)
+	defer stop()
+
+	key, err := keys.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	remote := keys.NewRemoteSigner(client, fake.AddKey(key))
+	local := keys.NewLocalSigner(key)
+
+	hash := make([]byte, 32)
+	hash[0] = 1
+
+	remoteSignature, err := remote.Sign(ctx, hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	localSignature, err := local.Sign(ctx, hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// ed25519 signatures are deterministic
+	if string(remoteSignature) != string(localSignature) {
+		t.Error("remote and local signatures differ")
+	}
+}
+
+func TestRemoteSignerRejectsBadSignature(t *testing.T) {
+	client, _, stop := serveSecretService(t)
+	defer stop()
+
+	ctx := context.Background()
+	signer, err := keys.GenerateRemoteSigner(ctx, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tampered := keys.NewRemoteSigner(tamperingClient{client}, signer.KeyID())
+	if _, err := tampered.Sign(ctx, make([]byte, 32)); err == nil {
+		t.Error("accepted a signature that does not match the public key")
+	}
+}
+
+func TestRemoteSignerUnknownKey(t *testing.T) {
+	client, _, stop := serveSecretService(t)
+	defer stop()
+
+	signer := keys.NewRemoteSigner(client, []byte("unknown"))
+	if _, err := signer.Sign(context.Background(), make([]byte, 32)); err == nil {
+		t.Error("signed with an unknown key")
+	}
+}
@@ -0,0 +1,109 @@
+package lcs
+
+import (
+	"encoding/hex"
+	"errors"
+)
+
+const (
+	AccountResourcePathHex = "01217da6c6b3e19f1825cfb2676daecce3bf3de03cf26647c78df00b371b25cc97"
+
+	SentEventsPath     = "/sent_events_count/"
+	ReceivedEventsPath = "/received_events_count/"
+)
+
+// AccountResourcePath is the access path of the 0x0.LibraAccount.T resource
+// inside an account.
+var AccountResourcePath, _ = hex.DecodeString(AccountResourcePathHex)
+
+type AccountResource struct {
+	AuthenticationKey             []byte
+	Balance                       uint64
+	DelegatedWithdrawalCapability bool
+	ReceivedEventsCount           uint64
+	SentEventsCount               uint64
+	SequenceNumber                uint64
+}
+
+// DecodeAccountStateBlob decodes an account state blob into its map of
+// access paths to serialized resources.
+func DecodeAccountStateBlob(blob []byte) (map[string][]byte, error) {
+	d := NewDecoder(blob)
+
+	resources, err := d.DecodeBytesMap()
+	if err != nil {
+		return nil, err
+	}
+
+	return resources, d.Finish()
+}
+
+func EncodeAccountStateBlob(resources map[string][]byte) []byte {
+	return NewEncoder().EncodeBytesMap(resources).Result()
+}
+
+func DecodeAccountResource(data []byte) (*AccountResource, error) {
+	d := NewDecoder(data)
+	result := AccountResource{}
+	var err error
+
+	if result.AuthenticationKey, err = d.DecodeBytes(); err != nil {
+		return nil, err
+	}
+	if result.Balance, err = d.DecodeU64(); err != nil {
+		return nil, err
+	}
+	if result.DelegatedWithdrawalCapability, err = d.DecodeBool(); err != nil {
+		return nil, err
+	}
+	if result.ReceivedEventsCount, err = d.DecodeU64(); err != nil {
+		return nil, err
+	}
+	if result.SentEventsCount, err = d.DecodeU64(); err != nil {
+		return nil, err
+	}
+	if result.SequenceNumber, err = d.DecodeU64(); err != nil {
+		return nil, err
+	}
+
+	return &result, d.Finish()
+}
+
+func (resource *AccountResource) Encode() []byte {
+	return NewEncoder().
+		EncodeBytes(resource.AuthenticationKey).
+		EncodeU64(resource.Balance).
+		EncodeBool(resource.DelegatedWithdrawalCapability).
+		EncodeU64(resource.ReceivedEventsCount).
+		EncodeU64(resource.SentEventsCount).
+		EncodeU64(resource.SequenceNumber).
+		Result()
+}
+
+// AccountResourceFromBlob finds and decodes the AccountResource of an account
+// state blob.
+func AccountResourceFromBlob(blob []byte) (*AccountResource, error) {
+	resources, err := DecodeAccountStateBlob(blob)
+	if err != nil {
+		return nil, err
+	}
+
+	data, ok := resources[string(AccountResourcePath)]
+	if !ok {
+		return nil, errors.New("lcs: account state blob has no account resource")
+	}
+
+	return DecodeAccountResource(data)
+}
+
+// AccountEventKey is the key of the events an account emits on path: the
+// account address followed by the event access path.
+func AccountEventKey(address []byte, path string) []byte {
+	return append(append([]byte{}, address...), AccountEventPath(path)...)
+}
+
+// AccountEventPath is the access path of an account's sent or received
+// payment events, e.g. AccountEventPath(SentEventsPath).
+func AccountEventPath(path string) []byte {
+	return append(append([]byte{}, AccountResourcePath...), path...)
+}
@@ -0,0 +1,213 @@
+package lcs
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Libra canonical serialization: integers are little endian, booleans are a
+// single byte, and byte arrays, strings and maps carry a u32 length prefix.
+// Map entries are ordered by the serialized bytes of their keys.
+
+const MaxLength = 1 << 31
+
+var ErrUnexpectedEnd = errors.New("lcs: unexpected end of input")
+
+type Decoder struct {
+	data []byte
+	pos  int
+}
+
+func NewDecoder(data []byte) *Decoder {
+	return &Decoder{data: data}
+}
+
+func (d *Decoder) Remaining() int {
+	return len(d.data) - d.pos
+}
+
+func (d *Decoder) Finish() error {
+	if d.Remaining() != 0 {
+		return fmt.Errorf("lcs: %d trailing bytes", d.Remaining())
+	}
+	return nil
+}
+
+func (d *Decoder) DecodeFixedBytes(n int) ([]byte, error) {
+	if n < 0 || d.Remaining() < n {
+		return nil, ErrUnexpectedEnd
+	}
+
+	buf := make([]byte, n)
+	copy(buf, d.data[d.pos:d.pos+n])
+	d.pos += n
+	return buf, nil
+}
+
+func (d *Decoder) DecodeU8() (uint8, error) {
+	buf, err := d.DecodeFixedBytes(1)
+	if err != nil {
+		return 0, err
+	}
+	return buf[0], nil
+}
+
+func (d *Decoder) DecodeBool() (bool, error) {
+	b, err := d.DecodeU8()
+	if err != nil {
+		return false, err
+	}
+
+	switch b {
+	case 0:
+		return false, nil
+	case 1:
+		return true, nil
+	default:
+		return false, fmt.Errorf("lcs: invalid bool value %d", b)
+	}
+}
+
+func (d *Decoder) DecodeU32() (uint32, error) {
+	buf, err := d.DecodeFixedBytes(4)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint32(buf), nil
+}
+
+func (d *Decoder) DecodeU64() (uint64, error) {
+	buf, err := d.DecodeFixedBytes(8)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint64(buf), nil
+}
+
+func (d *Decoder) DecodeLength() (int, error) {
+	length, err := d.DecodeU32()
+	if err != nil {
+		return 0, err
+	}
+
+	if uint64(length) >= MaxLength {
+		return 0, fmt.Errorf("lcs: length %d too large", length)
+	}
+	return int(length), nil
+}
+
+func (d *Decoder) DecodeBytes() ([]byte, error) {
+	length, err := d.DecodeLength()
+	if err != nil {
+		return nil, err
+	}
+	return d.DecodeFixedBytes(length)
+}
+
+func (d *Decoder) DecodeString() (string, error) {
+	buf, err := d.DecodeBytes()
+	return string(buf), err
+}
+
+// DecodeBytesMap decodes a map whose keys and values are both byte arrays.
+func (d *Decoder) DecodeBytesMap() (map[string][]byte, error) {
+	length, err := d.DecodeLength()
+	if err != nil {
+		return nil, err
+	}
+
+	// every entry takes at least its two length prefixes, which bounds the
+	// allocation by the input instead of by the untrusted length
+	if length > d.Remaining()/8 {
+		return nil, fmt.Errorf("lcs: map of %d entries does not fit in %d bytes", length, d.Remaining())
+	}
+
+	result := make(map[string][]byte, length)
+	for i := 0; i < length; i++ {
+		key, err := d.DecodeBytes()
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := d.DecodeBytes()
+		if err != nil {
+			return nil, err
+		}
+
+		if _, ok := result[string(key)]; ok {
+			return nil, errors.New("lcs: duplicate map key")
+		}
+		result[string(key)] = value
+	}
+
+	return result, nil
+}
+
+type Encoder struct {
+	buf bytes.Buffer
+}
+
+func NewEncoder() *Encoder {
+	return &Encoder{}
+}
+
+func (e *Encoder) Result() []byte {
+	return e.buf.Bytes()
+}
+
+func (e *Encoder) EncodeFixedBytes(b []byte) *Encoder {
+	e.buf.Write(b)
+	return e
+}
+
+func (e *Encoder) EncodeU8(v uint8) *Encoder {
+	e.buf.WriteByte(v)
+	return e
+}
+
+func (e *Encoder) EncodeBool(v bool) *Encoder {
+	if v {
+		return e.EncodeU8(1)
+	}
+	return e.EncodeU8(0)
+}
+
+func (e *Encoder) EncodeU32(v uint32) *Encoder {
+	var buf [4]byte
+	binary.LittleEndian.PutUint32(buf[:], v)
+	return e.EncodeFixedBytes(buf[:])
+}
+
+func (e *Encoder) EncodeU64(v uint64) *Encoder {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], v)
+	return e.EncodeFixedBytes(buf[:])
+}
+
+func (e *Encoder) EncodeBytes(b []byte) *Encoder {
+	return e.EncodeU32(uint32(len(b))).EncodeFixedBytes(b)
+}
+
+func (e *Encoder) EncodeString(s string) *Encoder {
+	return e.EncodeBytes([]byte(s))
+}
+
+func (e *Encoder) EncodeBytesMap(m map[string][]byte) *Encoder {
+	keys := make([][]byte, 0, len(m))
+	for k := range m {
+		keys = append(keys, NewEncoder().EncodeBytes([]byte(k)).Result())
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return bytes.Compare(keys[i], keys[j]) < 0
+	})
+
+	e.EncodeU32(uint32(len(m)))
+	for _, k := range keys {
+		e.EncodeFixedBytes(k)
+		e.EncodeBytes(m[string(k[4:])])
+	}
+	return e
+}
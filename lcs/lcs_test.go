@@ -0,0 +1,109 @@
+package lcs
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+// accountBlobHex is an account state blob holding a single AccountResource
+// with a 0x11.. authentication key, a balance of 1000000, 2 received and 3
+// sent events and sequence number 4.
+var accountBlobHex = strings.Join([]string{
+	"01000000", // one resource
+	"21000000", AccountResourcePathHex,
+	"45000000",
+	"20000000", strings.Repeat("11", 32),
+	"40420f0000000000", // balance
+	"00",               // delegated withdrawal capability
+	"0200000000000000", // received events count
+	"0300000000000000", // sent events count
+	"0400000000000000", // sequence number
+}, "")
+
+func TestAccountResourceFromBlob(t *testing.T) {
+	blob, err := hex.DecodeString(accountBlobHex)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resource, err := AccountResourceFromBlob(blob)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := AccountResource{
+		AuthenticationKey:   bytes.Repeat([]byte{0x11}, 32),
+		Balance:             1000000,
+		ReceivedEventsCount: 2,
+		SentEventsCount:     3,
+		SequenceNumber:      4,
+	}
+	if !bytes.Equal(resource.AuthenticationKey, want.AuthenticationKey) || resource.Balance != want.Balance ||
+		resource.DelegatedWithdrawalCapability || resource.ReceivedEventsCount != want.ReceivedEventsCount ||
+		resource.SentEventsCount != want.SentEventsCount || resource.SequenceNumber != want.SequenceNumber {
+		t.Errorf("got %+v, want %+v", resource, want)
+	}
+
+	if encoded := hex.EncodeToString(EncodeAccountStateBlob(map[string][]byte{string(AccountResourcePath): want.Encode()})); encoded != accountBlobHex {
+		t.Errorf("encoded blob %s, want %s", encoded, accountBlobHex)
+	}
+}
+
+func TestDecodeBadInput(t *testing.T) {
+	blob, err := hex.DecodeString(accountBlobHex)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		data   string
+		decode func(d *Decoder) error
+	}{
+		{"empty u64", "", func(d *Decoder) error { _, err := d.DecodeU64(); return err }},
+		{"truncated u64", "0102030405", func(d *Decoder) error { _, err := d.DecodeU64(); return err }},
+		{"bad bool", "02", func(d *Decoder) error { _, err := d.DecodeBool(); return err }},
+		{"truncated length", "0100", func(d *Decoder) error { _, err := d.DecodeBytes(); return err }},
+		{"truncated bytes", "05000000010203", func(d *Decoder) error { _, err := d.DecodeBytes(); return err }},
+		{"length over max", "00000080", func(d *Decoder) error { _, err := d.DecodeBytes(); return err }},
+		{"bytes longer than input", "ffffff7f00", func(d *Decoder) error { _, err := d.DecodeBytes(); return err }},
+		{"map longer than input", "ffffff7f", func(d *Decoder) error { _, err := d.DecodeBytesMap(); return err }},
+		{"map entries do not fit", "0200000001000000aa01000000bb", func(d *Decoder) error { _, err := d.DecodeBytesMap(); return err }},
+		{"map with truncated value", "0100000001000000aa05000000bb", func(d *Decoder) error { _, err := d.DecodeBytesMap(); return err }},
+		{"duplicate map key", "0200000001000000aa01000000bb01000000aa01000000cc", func(d *Decoder) error { _, err := d.DecodeBytesMap(); return err }},
+		{"truncated account blob", hex.EncodeToString(blob[:len(blob)-1]), func(d *Decoder) error { _, err := AccountResourceFromBlob(d.data); return err }},
+		{"account blob with trailing bytes", hex.EncodeToString(append(blob, 0)), func(d *Decoder) error { _, err := AccountResourceFromBlob(d.data); return err }},
+		{"account blob without resource", "00000000", func(d *Decoder) error { _, err := AccountResourceFromBlob(d.data); return err }},
+	}
+
+	for _, tt := range tests {
+		data, err := hex.DecodeString(tt.data)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := tt.decode(NewDecoder(data)); err == nil {
+			t.Errorf("%s: decoded without error", tt.name)
+		}
+	}
+}
+
+func TestEncodeBytesMapOrder(t *testing.T) {
+	m := map[string][]byte{"b": {2}, "a": {1}, "aa": {3}}
+
+	encoded := NewEncoder().EncodeBytesMap(m).Result()
+	want := "03000000" + "01000000" + "61" + "01000000" + "01" + "01000000" + "62" + "01000000" + "02" + "02000000" + "6161" + "01000000" + "03"
+	if hex.EncodeToString(encoded) != want {
+		t.Errorf("got %x, want %s", encoded, want)
+	}
+
+	decoded, err := NewDecoder(encoded).DecodeBytesMap()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded) != 3 || !bytes.Equal(decoded["aa"], []byte{3}) {
+		t.Errorf("got %v", decoded)
+	}
+}
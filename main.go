@@ -1,81 +1,103 @@
 package main
 
 import (
+	"context"
+	"log"
+	"net"
+	"net/http"
 	"os"
-	"strconv"
+	"os/signal"
+	"syscall"
+	"time"
 
-	"github.com/gin-gonic/gin"
+	"io.librablock.go/api"
 	"io.librablock.go/controllers"
+	"io.librablock.go/fetcher"
 	"io.librablock.go/utils"
+	"io.librablock.go/verifier"
 )
 
-func main() {
-	dbURL := os.Getenv("LIBRA_MYSQL_URL")
-	db := utils.NewDataBaseAdapter(dbURL)
-	db.Migration()
-
-	r := gin.Default()
+// demoHistory is how many versions behind the tip the demo mode starts.
+const demoHistory = 1000
 
-	r.GET("/version", func(c *gin.Context) {
-		offsetStr := c.DefaultQuery("offset", "0")
-		limitStr := c.DefaultQuery("limit", "20")
-		address := c.Query("address")
+func main() {
+	dbURL := utils.DatabaseURLFromEnv()
+	db, err := utils.OpenStore(dbURL, utils.DBOptionsFromEnv())
+	if err != nil {
+		log.Fatalf("failed to connect database: %v", err)
+	}
+	defer db.Close()
+
+	if err := db.Migration(); err != nil {
+		log.Fatalf("failed to migrate database: %v", err)
+	}
+
+	rpc, err := controllers.NewLibraRPC(controllers.AddressFromEnv(), controllers.RPCOptionsFromEnv())
+	if err != nil {
+		log.Fatalf("failed to set up rpc: %v", err)
+	}
+	defer rpc.Close()
+
+	if path := os.Getenv("LIBRA_TRUSTED_STATE"); path != "" {
+		rpc.TrustedState, err = verifier.LoadTrustedState(path)
+		if err != nil {
+			log.Fatalf("failed to load trusted state: %v", err)
+		}
+	}
 
-		offset, err1 := strconv.Atoi(offsetStr)
-		limit, err2 := strconv.Atoi(limitStr)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
-		if err1 != nil || err2 != nil {
-			c.JSON(400, gin.H{"message": "bad request"})
-			return
+	if _, ok := db.(*utils.MemoryStore); ok {
+		// demo mode: nothing else fills the store, so follow the chain from
+		// a little behind its tip in process
+		latest, err := rpc.GetLatestVersion(ctx)
+		if err != nil {
+			log.Fatalf("failed to get latest version: %v", err)
 		}
-
-		if address == "" {
-			c.JSON(200, db.GetVersions(offset, limit))
-		} else {
-			c.JSON(200, db.GetVersionsRefAddress(address, offset, limit))
+		if latest > demoHistory {
+			if err := db.SaveCheckpoint(latest - demoHistory); err != nil {
+				log.Fatalf("failed to save checkpoint: %v", err)
+			}
 		}
 
-	})
+		go func() {
+			if err := fetcher.Run(ctx, rpc, db, fetcher.BackfillOptions{}); err != nil {
+				log.Printf("demo fetcher stopped: %v", err)
+			}
+		}()
+	}
 
-	r.GET("/version/:id", func(c *gin.Context) {
-		id := c.Param("id")
-		id64, err := strconv.ParseInt(id, 10, 64)
+	r := api.NewRouter(db, rpc)
 
-		if err != nil {
-			c.JSON(400, gin.H{"message": "bad request"})
-			return
-		}
+	srv := &http.Server{
+		Addr:    "127.0.0.1:2222",
+		Handler: r,
+		// cancels in-flight node calls of every request on shutdown
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
 
-		version := db.GetVersion(uint64(id64))
-		if version.ID == 0 {
-			c.JSON(404, gin.H{"message": "not found"})
-		} else {
-			c.JSON(200, version)
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("listen: %v", err)
 		}
-	})
+	}()
 
-	r.GET("/account/:address", func(c *gin.Context) {
-		address := c.Param("address")
-		_, err := controllers.HexToBytes(address)
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	<-signals
 
-		if len(address) != 64 || err != nil {
-			c.JSON(400, gin.H{"message": "bad request"})
-			return
-		}
+	cancel()
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer shutdownCancel()
 
-		rpc := controllers.NewLibraRPC(nil)
-		r, err := rpc.GetAccountState(address)
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("shutdown: %v", err)
+	}
 
-		if err != nil {
-			c.JSON(400, gin.H{"message": "bad request"})
-		} else {
-			if r == nil {
-				c.JSON(404, gin.H{"message": "not found"})
-			} else {
-				c.JSON(200, r)
-			}
+	if rpc.TrustedState != nil {
+		if err := rpc.TrustedState.Save(); err != nil {
+			log.Printf("failed to save trusted state: %v", err)
 		}
-	})
-
-	r.Run("127.0.0.1:2222")
+	}
 }
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"io.librablock.go/migrations"
+	"io.librablock.go/utils"
+)
+
+func usage() {
+	fmt.Println("usage: migrate up | down | status")
+	os.Exit(2)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	db, err := utils.NewDataBaseAdapter(utils.DatabaseURLFromEnv(), utils.DBOptionsFromEnv())
+	if err != nil {
+		fmt.Printf("Failed to connect database: %s\n", err.Error())
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	switch os.Args[1] {
+	case "up":
+		applied, err := migrations.Up(db.GetDB(), db.Dialect())
+		for _, m := range applied {
+			fmt.Printf("Applied %d %s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			fmt.Printf("Failed to migrate: %s\n", err.Error())
+			os.Exit(1)
+		}
+		if len(applied) == 0 {
+			fmt.Println("Already up to date")
+		}
+	case "down":
+		reverted, err := migrations.Down(db.GetDB(), db.Dialect())
+		if err != nil {
+			fmt.Printf("Failed to migrate: %s\n", err.Error())
+			os.Exit(1)
+		}
+		if reverted == nil {
+			fmt.Println("Nothing to revert")
+		} else {
+			fmt.Printf("Reverted %d %s\n", reverted.Version, reverted.Name)
+		}
+	case "status":
+		statuses, err := migrations.Status(db.GetDB(), db.Dialect())
+		if err != nil {
+			fmt.Printf("Failed to read schema version: %s\n", err.Error())
+			os.Exit(1)
+		}
+
+		for _, s := range statuses {
+			if s.AppliedAt == nil {
+				fmt.Printf("%04d %s pending\n", s.Version, s.Name)
+			} else {
+				fmt.Printf("%04d %s applied %s\n", s.Version, s.Name, s.AppliedAt.Format("2006-01-02 15:04:05"))
+			}
+		}
+	default:
+		usage()
+	}
+}
@@ -0,0 +1,67 @@
+package migrations
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// blockModel0001 is block_models as the releases before schema versioning
+// created it with AutoMigrate. The plain index on version is managed by the
+// migration itself. Later migrations must not change this struct.
+type blockModel0001 struct {
+	ID             uint `gorm:"primary_key"`
+	CreatedAt      time.Time
+	ExpirationAt   time.Time
+	Version        uint64
+	Source         string `gorm:"index:source"`
+	Destination    string `gorm:"index:destination"`
+	Type           string `gorm:"index:type"`
+	Amount         uint64
+	GasPrice       uint64
+	MaxGas         uint64
+	SequenceNumber uint64
+	PublicKey      string
+	MD5            string
+}
+
+func (blockModel0001) TableName() string {
+	return "block_models"
+}
+
+// uniqueBlockVersion creates block_models on a fresh database and replaces
+// the plain index on its version by a unique one, keeping the first stored
+// block of every duplicated version. Down restores the plain index but keeps
+// the table, which deployments older than this migration already had.
+var uniqueBlockVersion = Migration{
+	Version: 1,
+	Name:    "unique_block_version",
+
+	Up: func(tx *gorm.DB, dialect string) error {
+		if err := tx.AutoMigrate(&blockModel0001{}).Error; err != nil {
+			return err
+		}
+
+		// the derived table lets MySQL read the table it deletes from
+		err := tx.Exec("DELETE FROM block_models WHERE id NOT IN (SELECT id FROM (SELECT MIN(id) AS id FROM block_models GROUP BY version) AS kept)").Error
+		if err != nil {
+			return err
+		}
+
+		if tx.Dialect().HasIndex("block_models", "version") {
+			if err := tx.Dialect().RemoveIndex("block_models", "version"); err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&blockModel0001{}).AddUniqueIndex("block_version", "version").Error
+	},
+
+	Down: func(tx *gorm.DB, dialect string) error {
+		if err := tx.Dialect().RemoveIndex("block_models", "block_version"); err != nil {
+			return err
+		}
+
+		return tx.Model(&blockModel0001{}).AddIndex("version", "version").Error
+	},
+}
@@ -0,0 +1,91 @@
+package migrations
+
+import (
+	"github.com/jinzhu/gorm"
+)
+
+// blockModel0002 holds the columns block_models gained to keep the proven
+// transaction info of every block.
+type blockModel0002 struct {
+	GasUsed               uint64
+	SignedTransactionHash string `gorm:"index:signed_transaction_hash"`
+	StateRootHash         string
+	EventRootHash         string
+}
+
+func (blockModel0002) TableName() string {
+	return "block_models"
+}
+
+// the block_models columns of migration 1, which the SQLite Down copies over
+const blockColumns0001 = "id, created_at, expiration_at, version, source, destination, type, amount, gas_price, max_gas, sequence_number, public_key, md5"
+
+// blockInfoColumns adds the gas used and the transaction, state and event
+// hashes to block_models. Older deployments that ran AutoMigrate on a newer
+// model may already have them.
+var blockInfoColumns = Migration{
+	Version: 2,
+	Name:    "block_info_columns",
+
+	Up: func(tx *gorm.DB, dialect string) error {
+		return tx.AutoMigrate(&blockModel0002{}).Error
+	},
+
+	Down: func(tx *gorm.DB, dialect string) error {
+		if dialect == SQLiteDialect {
+			return rebuildBlockModels0001(tx)
+		}
+
+		if err := tx.Dialect().RemoveIndex("block_models", "signed_transaction_hash"); err != nil {
+			return err
+		}
+
+		for _, column := range []string{"gas_used", "signed_transaction_hash", "state_root_hash", "event_root_hash"} {
+			if err := tx.Model(&blockModel0002{}).DropColumn(column).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+// rebuildBlockModels0001 copies block_models into a new table with the
+// columns and indexes of migration 1, since the bundled SQLite cannot drop
+// columns.
+func rebuildBlockModels0001(tx *gorm.DB) error {
+	var indexes []string
+	err := tx.Raw("SELECT name FROM sqlite_master WHERE type = 'index' AND tbl_name = 'block_models' AND sql IS NOT NULL").Pluck("name", &indexes).Error
+	if err != nil {
+		return err
+	}
+
+	// index names are global in SQLite, so the new table cannot be created
+	// while the old one keeps them
+	for _, index := range indexes {
+		if err := tx.Dialect().RemoveIndex("block_models", index); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Exec("ALTER TABLE block_models RENAME TO block_models_0002").Error; err != nil {
+		return err
+	}
+
+	if err := tx.CreateTable(&blockModel0001{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&blockModel0001{}).AddUniqueIndex("block_version", "version").Error; err != nil {
+		return err
+	}
+
+	statements := []string{
+		"INSERT INTO block_models (" + blockColumns0001 + ") SELECT " + blockColumns0001 + " FROM block_models_0002",
+		"DROP TABLE block_models_0002",
+	}
+	for _, statement := range statements {
+		if err := tx.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
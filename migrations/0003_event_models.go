@@ -0,0 +1,44 @@
+package migrations
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+type eventModel0003 struct {
+	ID             uint `gorm:"primary_key"`
+	CreatedAt      time.Time
+	Version        uint64 `gorm:"index:event_version"`
+	EventIndex     uint64
+	Key            string `gorm:"column:event_key;index:event_key"`
+	SequenceNumber uint64
+	Data           string `gorm:"type:text"`
+	Type           string `gorm:"index:event_type"`
+	Amount         uint64
+	Counterparty   string `gorm:"index:counterparty"`
+}
+
+func (eventModel0003) TableName() string {
+	return "event_models"
+}
+
+// eventModels creates the table of the events emitted by every block. A
+// deployment that already created it with AutoMigrate stored the events again
+// with every duplicated block, so all but the first copy of each are deleted.
+var eventModels = Migration{
+	Version: 3,
+	Name:    "event_models",
+
+	Up: func(tx *gorm.DB, dialect string) error {
+		if err := tx.AutoMigrate(&eventModel0003{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Exec("DELETE FROM event_models WHERE id NOT IN (SELECT id FROM (SELECT MIN(id) AS id FROM event_models GROUP BY version, event_index) AS kept)").Error
+	},
+
+	Down: func(tx *gorm.DB, dialect string) error {
+		return tx.DropTable(&eventModel0003{}).Error
+	},
+}
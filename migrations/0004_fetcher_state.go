@@ -0,0 +1,31 @@
+package migrations
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+type fetcherStateModel0004 struct {
+	ID        uint `gorm:"primary_key"`
+	UpdatedAt time.Time
+	Version   uint64
+}
+
+func (fetcherStateModel0004) TableName() string {
+	return "fetcher_state_models"
+}
+
+// fetcherState creates the table of the fetcher checkpoint.
+var fetcherState = Migration{
+	Version: 4,
+	Name:    "fetcher_state",
+
+	Up: func(tx *gorm.DB, dialect string) error {
+		return tx.AutoMigrate(&fetcherStateModel0004{}).Error
+	},
+
+	Down: func(tx *gorm.DB, dialect string) error {
+		return tx.DropTable(&fetcherStateModel0004{}).Error
+	},
+}
@@ -0,0 +1,182 @@
+// Package migrations evolves the database schema in numbered, reversible
+// steps. The version applied last is kept in the schema_version table.
+package migrations
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// The gorm dialects migrations tell apart. Package utils, which opens the
+// databases, exports the same names.
+const (
+	MySQLDialect    = "mysql"
+	PostgresDialect = "postgres"
+	SQLiteDialect   = "sqlite3"
+)
+
+// Migration is one schema change. Up and Down run in a transaction on the
+// database of the given gorm dialect; MySQL commits DDL statements on its own,
+// so there a failed step may be left half applied.
+type Migration struct {
+	Version uint
+	Name    string
+	Up      func(tx *gorm.DB, dialect string) error
+	Down    func(tx *gorm.DB, dialect string) error
+}
+
+// all lists every migration in the order they are applied. Append new ones,
+// never renumber or edit one that has been released.
+var all = []Migration{
+	uniqueBlockVersion,
+	blockInfoColumns,
+	eventModels,
+	fetcherState,
+}
+
+type schemaVersion struct {
+	Version   uint `gorm:"primary_key;auto_increment:false"`
+	Name      string
+	AppliedAt time.Time
+}
+
+func (schemaVersion) TableName() string {
+	return "schema_version"
+}
+
+// MigrationStatus is a known migration and when it was applied, nil while
+// it is pending.
+type MigrationStatus struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+func session(db *gorm.DB, dialect string) (*gorm.DB, error) {
+	if dialect == MySQLDialect {
+		db = db.Set("gorm:table_options", "ENGINE=InnoDB DEFAULT CHARSET=utf8mb4")
+	}
+
+	return db, db.AutoMigrate(&schemaVersion{}).Error
+}
+
+func currentVersion(db *gorm.DB) (uint, error) {
+	current := schemaVersion{}
+	err := db.Order("version desc").First(&current).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return 0, nil
+	}
+
+	return current.Version, err
+}
+
+func apply(db *gorm.DB, dialect string, step func(tx *gorm.DB, dialect string) error, record func(tx *gorm.DB) error) error {
+	tx := db.Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	if err := step(tx, dialect); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := record(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// Up applies every pending migration in order and returns those applied.
+func Up(db *gorm.DB, dialect string) ([]Migration, error) {
+	db, err := session(db, dialect)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := currentVersion(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []Migration
+	for _, m := range all {
+		if m.Version <= current {
+			continue
+		}
+
+		err := apply(db, dialect, m.Up, func(tx *gorm.DB) error {
+			return tx.Create(&schemaVersion{Version: m.Version, Name: m.Name, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return applied, fmt.Errorf("migration %d %s: %v", m.Version, m.Name, err)
+		}
+
+		applied = append(applied, m)
+	}
+
+	return applied, nil
+}
+
+// Down reverts the latest applied migration and returns it, or nil when
+// there is nothing to revert.
+func Down(db *gorm.DB, dialect string) (*Migration, error) {
+	db, err := session(db, dialect)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := currentVersion(db)
+	if err != nil || current == 0 {
+		return nil, err
+	}
+
+	for _, m := range all {
+		if m.Version != current {
+			continue
+		}
+
+		err := apply(db, dialect, m.Down, func(tx *gorm.DB) error {
+			return tx.Delete(&schemaVersion{Version: m.Version}).Error
+		})
+		if err != nil {
+			return nil, fmt.Errorf("migration %d %s: %v", m.Version, m.Name, err)
+		}
+
+		return &m, nil
+	}
+
+	return nil, fmt.Errorf("schema version %d is unknown to this binary", current)
+}
+
+// Status returns every known migration in order.
+func Status(db *gorm.DB, dialect string) ([]MigrationStatus, error) {
+	db, err := session(db, dialect)
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []schemaVersion
+	if err := db.Find(&versions).Error; err != nil {
+		return nil, err
+	}
+
+	appliedAt := map[uint]time.Time{}
+	for _, v := range versions {
+		appliedAt[v.Version] = v.AppliedAt
+	}
+
+	result := make([]MigrationStatus, 0, len(all))
+	for _, m := range all {
+		s := MigrationStatus{Migration: m}
+		if t, ok := appliedAt[m.Version]; ok {
+			s.AppliedAt = &t
+		}
+		result = append(result, s)
+	}
+
+	return result, nil
+}
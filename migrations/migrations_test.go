@@ -0,0 +1,199 @@
+package migrations
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+)
+
+func openSQLite(t *testing.T) (*gorm.DB, func()) {
+	dir, err := ioutil.TempDir("", "libra-migrations")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := gorm.Open(SQLiteDialect, filepath.Join(dir, "test.db"))
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+
+	return db, func() {
+		db.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func checkVersion(t *testing.T, db *gorm.DB, want uint) {
+	t.Helper()
+
+	current, err := currentVersion(db)
+	if err != nil || current != want {
+		t.Fatalf("schema version %d, %v, want %d", current, err, want)
+	}
+}
+
+func checkUnique(t *testing.T, db *gorm.DB, want bool) {
+	t.Helper()
+
+	db.Exec("DELETE FROM block_models WHERE version = 1000")
+	first := db.Create(&blockModel0001{Version: 1000}).Error
+	second := db.Create(&blockModel0001{Version: 1000}).Error
+	db.Exec("DELETE FROM block_models WHERE version = 1000")
+
+	if first != nil || (second != nil) != want {
+		t.Fatalf("inserting a version twice: %v, %v, want unique %v", first, second, want)
+	}
+}
+
+// checkReverted checks that only block_models is left, with the columns the
+// releases before schema versioning had.
+func checkReverted(t *testing.T, db *gorm.DB) {
+	t.Helper()
+
+	for _, table := range []string{"event_models", "fetcher_state_models"} {
+		if db.HasTable(table) {
+			t.Errorf("%s was not dropped", table)
+		}
+	}
+
+	for _, column := range []string{"gas_used", "signed_transaction_hash", "state_root_hash", "event_root_hash"} {
+		if db.Dialect().HasColumn("block_models", column) {
+			t.Errorf("block_models still has a %s column", column)
+		}
+	}
+	for _, index := range []string{"signed_transaction_hash", "block_version"} {
+		if db.Dialect().HasIndex("block_models", index) {
+			t.Errorf("block_models still has a %s index", index)
+		}
+	}
+	if !db.Dialect().HasIndex("block_models", "version") {
+		t.Error("the version index was not restored")
+	}
+
+	var blocks []blockModel0001
+	if err := db.Find(&blocks).Error; err != nil || len(blocks) != 1 || blocks[0].Version != 1 || blocks[0].Amount != 10 {
+		t.Errorf("got blocks %+v, %v", blocks, err)
+	}
+}
+
+func TestUpDownUp(t *testing.T) {
+	db, stop := openSQLite(t)
+	defer stop()
+
+	applied, err := Up(db, SQLiteDialect)
+	if err != nil || len(applied) != len(all) {
+		t.Fatalf("got %d migrations, %v", len(applied), err)
+	}
+	checkVersion(t, db, all[len(all)-1].Version)
+	checkUnique(t, db, true)
+
+	// the SQLite Down of migration 2 copies the blocks into a new table
+	if err := db.Create(&blockModel0001{Version: 1, Amount: 10}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if applied, err := Up(db, SQLiteDialect); err != nil || len(applied) != 0 {
+		t.Fatalf("reapplied %d migrations, %v", len(applied), err)
+	}
+
+	for i := len(all) - 1; i >= 0; i-- {
+		reverted, err := Down(db, SQLiteDialect)
+		if err != nil || reverted == nil || reverted.Version != all[i].Version {
+			t.Fatalf("reverted %v, %v, want %d", reverted, err, all[i].Version)
+		}
+	}
+	checkVersion(t, db, 0)
+	checkUnique(t, db, false)
+	checkReverted(t, db)
+
+	if reverted, err := Down(db, SQLiteDialect); err != nil || reverted != nil {
+		t.Fatalf("reverted %v, %v with nothing applied", reverted, err)
+	}
+
+	if applied, err := Up(db, SQLiteDialect); err != nil || len(applied) != len(all) {
+		t.Fatalf("got %d migrations, %v", len(applied), err)
+	}
+	checkVersion(t, db, all[len(all)-1].Version)
+	checkUnique(t, db, true)
+
+	statuses, err := Status(db, SQLiteDialect)
+	if err != nil || len(statuses) != len(all) || statuses[0].AppliedAt == nil {
+		t.Fatalf("got %+v, %v", statuses, err)
+	}
+}
+
+// the block table as the first releases created it, before GasUsed and the
+// hashes were added and without a unique version
+type oldBlockModel struct {
+	ID        uint `gorm:"primary_key"`
+	CreatedAt time.Time
+	Version   uint64 `gorm:"index:version"`
+	Source    string
+	Amount    uint64
+}
+
+func (oldBlockModel) TableName() string {
+	return "block_models"
+}
+
+func TestUpgradeExistingTables(t *testing.T) {
+	db, stop := openSQLite(t)
+	defer stop()
+
+	if err := db.CreateTable(&oldBlockModel{}, &eventModel0003{}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// version 2 was stored twice, each time with its two events
+	for _, block := range []oldBlockModel{{Version: 1, Amount: 10}, {Version: 2, Amount: 20}, {Version: 2, Amount: 21}} {
+		if err := db.Create(&block).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, event := range []eventModel0003{
+		{Version: 1, EventIndex: 0},
+		{Version: 2, EventIndex: 0, Amount: 20}, {Version: 2, EventIndex: 1, Amount: 20},
+		{Version: 2, EventIndex: 0, Amount: 21}, {Version: 2, EventIndex: 1, Amount: 21},
+	} {
+		if err := db.Create(&event).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := Up(db, SQLiteDialect); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, column := range []string{"gas_used", "signed_transaction_hash", "state_root_hash", "event_root_hash", "expiration_at"} {
+		if !db.Dialect().HasColumn("block_models", column) {
+			t.Errorf("block_models has no %s column", column)
+		}
+	}
+	if !db.HasTable(&fetcherStateModel0004{}) {
+		t.Error("fetcher_state_models was not created")
+	}
+
+	var blocks []blockModel0001
+	if err := db.Order("version").Find(&blocks).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(blocks) != 2 || blocks[1].Version != 2 || blocks[1].Amount != 20 {
+		t.Errorf("got blocks %+v", blocks)
+	}
+
+	var events []eventModel0003
+	if err := db.Where("version = ?", 2).Order("event_index").Find(&events).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 || events[0].Amount != 20 || events[1].Amount != 20 || events[1].EventIndex != 1 {
+		t.Errorf("got events %+v", events)
+	}
+
+	checkUnique(t, db, true)
+}
@@ -6,7 +6,7 @@ type BlockModel struct {
 	ID             uint      `gorm:"primary_key" json:"-"`
 	CreatedAt      time.Time `json:"created_at"`
 	ExpirationAt   time.Time `json:"expiration_at"`
-	Version        uint64    `json:"version" gorm:"index:version"`
+	Version        uint64    `json:"version" gorm:"unique_index:block_version"`
 	Source         string    `json:"source" gorm:"index:source"`
 	Destination    string    `json:"destination" gorm:"index:destination"`
 	Type           string    `json:"type" gorm:"index:type"`
@@ -16,6 +16,34 @@ type BlockModel struct {
 	SequenceNumber uint64    `json:"sequence_number" `
 	PublicKey      string    `json:"public_key"`
 	MD5            string    `json:"-" `
+
+	GasUsed               uint64 `json:"gas_used"`
+	SignedTransactionHash string `json:"signed_transaction_hash" gorm:"index:signed_transaction_hash"`
+	StateRootHash         string `json:"state_root_hash"`
+	EventRootHash         string `json:"event_root_hash"`
+
+	Events []EventModel `json:"-" gorm:"-"`
+}
+
+type EventModel struct {
+	ID             uint      `gorm:"primary_key" json:"-"`
+	CreatedAt      time.Time `json:"created_at"`
+	Version        uint64    `json:"version" gorm:"index:event_version"`
+	EventIndex     uint64    `json:"event_index"`
+	Key            string    `json:"key" gorm:"column:event_key;index:event_key"`
+	SequenceNumber uint64    `json:"sequence_number"`
+	Data           string    `json:"data" gorm:"type:text"`
+	Type           string    `json:"type" gorm:"index:event_type"`
+	Amount         uint64    `json:"amount"`
+	Counterparty   string    `json:"counterparty" gorm:"index:counterparty"`
+}
+
+// FetcherStateModel is the single row recording the last version the block
+// fetcher has stored.
+type FetcherStateModel struct {
+	ID        uint `gorm:"primary_key"`
+	UpdatedAt time.Time
+	Version   uint64
 }
 
 type AccountModel struct {
@@ -25,4 +53,42 @@ type AccountModel struct {
 	SentEventCount     uint64 `json:"sent_event_count"`
 	ReceivedEventCount uint64 `json:"received_event_count"`
 	AuthenticationKey  string `json:"authentication_key"`
+
+	Verified      bool   `json:"verified"`
+	Version       uint64 `json:"version"`
+	LedgerVersion uint64 `json:"ledger_version"`
+	StateRootHash string `json:"state_root_hash"`
+}
+
+type SubmitTransactionModel struct {
+	Accepted    bool              `json:"accepted"`
+	ValidatorID string            `json:"validator_id,omitempty"`
+	Error       *SubmitErrorModel `json:"error,omitempty"`
+}
+
+type SubmitErrorModel struct {
+	Source  string `json:"source"`
+	Type    string `json:"type,omitempty"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type AccountTransactionModel struct {
+	Address               string       `json:"address"`
+	SequenceNumber        uint64       `json:"sequence_number"`
+	Committed             bool         `json:"committed"`
+	Transaction           *BlockModel  `json:"transaction,omitempty"`
+	Events                []EventModel `json:"events,omitempty"`
+	AccountSequenceNumber uint64       `json:"account_sequence_number"`
+	LedgerVersion         uint64       `json:"ledger_version"`
+	Verified              bool         `json:"verified"`
+}
+
+type AccountEventsModel struct {
+	Address       string       `json:"address"`
+	Type          string       `json:"type"`
+	Events        []EventModel `json:"events"`
+	EventCount    *uint64      `json:"event_count,omitempty"`
+	LedgerVersion uint64       `json:"ledger_version"`
+	Verified      bool         `json:"verified"`
 }
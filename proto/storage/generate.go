@@ -0,0 +1,9 @@
+package storage
+
+// storage.proto imports the types protos of the Libra checkout in
+// $LIBRA_TYPES_PROTO (types/src/proto), which map to package types. Build
+// protoc-gen-go from the golang/protobuf version pinned in go.mod:
+//
+//	go install github.com/golang/protobuf/protoc-gen-go
+//
+//go:generate protoc -I . -I ${LIBRA_TYPES_PROTO} --go_out=plugins=grpc,Mget_with_proof.proto=io.librablock.go/proto/types,Mledger_info.proto=io.librablock.go/proto/types,Mtransaction.proto=io.librablock.go/proto/types,Maccount_state_blob.proto=io.librablock.go/proto/types,Mproof.proto=io.librablock.go/proto/types:. storage.proto
@@ -10,6 +10,7 @@ import (
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
 	status "google.golang.org/grpc/status"
+	types "io.librablock.go/proto/types"
 	math "math"
 )
 
@@ -26,16 +27,16 @@ const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 
 type SaveTransactionsRequest struct {
 	// Transactions to persist.
-	TxnsToCommit []*TransactionToCommit `protobuf:"bytes,1,rep,name=txns_to_commit,json=txnsToCommit,proto3" json:"txns_to_commit,omitempty"`
+	TxnsToCommit []*types.TransactionToCommit `protobuf:"bytes,1,rep,name=txns_to_commit,json=txnsToCommit,proto3" json:"txns_to_commit,omitempty"`
 	// The version of the first transaction in `txns_to_commit`.
 	FirstVersion uint64 `protobuf:"varint,2,opt,name=first_version,json=firstVersion,proto3" json:"first_version,omitempty"`
 	// If this is set, Storage will check its state after applying the above
 	// transactions matches info in this LedgerInfo before committing otherwise
 	// it denies the request.
-	LedgerInfoWithSignatures *LedgerInfoWithSignatures `protobuf:"bytes,3,opt,name=ledger_info_with_signatures,json=ledgerInfoWithSignatures,proto3" json:"ledger_info_with_signatures,omitempty"`
-	XXX_NoUnkeyedLiteral     struct{}                  `json:"-"`
-	XXX_unrecognized         []byte                    `json:"-"`
-	XXX_sizecache            int32                     `json:"-"`
+	LedgerInfoWithSignatures *types.LedgerInfoWithSignatures `protobuf:"bytes,3,opt,name=ledger_info_with_signatures,json=ledgerInfoWithSignatures,proto3" json:"ledger_info_with_signatures,omitempty"`
+	XXX_NoUnkeyedLiteral     struct{}                        `json:"-"`
+	XXX_unrecognized         []byte                          `json:"-"`
+	XXX_sizecache            int32                           `json:"-"`
 }
 
 func (m *SaveTransactionsRequest) Reset()         { *m = SaveTransactionsRequest{} }
@@ -63,7 +64,7 @@ func (m *SaveTransactionsRequest) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_SaveTransactionsRequest proto.InternalMessageInfo
 
-func (m *SaveTransactionsRequest) GetTxnsToCommit() []*TransactionToCommit {
+func (m *SaveTransactionsRequest) GetTxnsToCommit() []*types.TransactionToCommit {
 	if m != nil {
 		return m.TxnsToCommit
 	}
@@ -77,7 +78,7 @@ func (m *SaveTransactionsRequest) GetFirstVersion() uint64 {
 	return 0
 }
 
-func (m *SaveTransactionsRequest) GetLedgerInfoWithSignatures() *LedgerInfoWithSignatures {
+func (m *SaveTransactionsRequest) GetLedgerInfoWithSignatures() *types.LedgerInfoWithSignatures {
 	if m != nil {
 		return m.LedgerInfoWithSignatures
 	}
@@ -184,10 +185,10 @@ func (m *GetTransactionsRequest) GetFetchEvents() bool {
 }
 
 type GetTransactionsResponse struct {
-	TxnListWithProof     *TransactionListWithProof `protobuf:"bytes,1,opt,name=txn_list_with_proof,json=txnListWithProof,proto3" json:"txn_list_with_proof,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}                  `json:"-"`
-	XXX_unrecognized     []byte                    `json:"-"`
-	XXX_sizecache        int32                     `json:"-"`
+	TxnListWithProof     *types.TransactionListWithProof `protobuf:"bytes,1,opt,name=txn_list_with_proof,json=txnListWithProof,proto3" json:"txn_list_with_proof,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                        `json:"-"`
+	XXX_unrecognized     []byte                          `json:"-"`
+	XXX_sizecache        int32                           `json:"-"`
 }
 
 func (m *GetTransactionsResponse) Reset()         { *m = GetTransactionsResponse{} }
@@ -215,7 +216,7 @@ func (m *GetTransactionsResponse) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_GetTransactionsResponse proto.InternalMessageInfo
 
-func (m *GetTransactionsResponse) GetTxnListWithProof() *TransactionListWithProof {
+func (m *GetTransactionsResponse) GetTxnListWithProof() *types.TransactionListWithProof {
 	if m != nil {
 		return m.TxnListWithProof
 	}
@@ -275,19 +276,21 @@ func (m *GetAccountStateWithProofByVersionRequest) GetVersion() uint64 {
 
 type GetAccountStateWithProofByVersionResponse struct {
 	/// The optional blob of account state blob.
-	AccountStateBlob *AccountStateBlob `protobuf:"bytes,1,opt,name=account_state_blob,json=accountStateBlob,proto3" json:"account_state_blob,omitempty"`
+	AccountStateBlob *types.AccountStateBlob `protobuf:"bytes,1,opt,name=account_state_blob,json=accountStateBlob,proto3" json:"account_state_blob,omitempty"`
 	/// The state root hash the query is based on.
-	SparseMerkleProof    *SparseMerkleProof `protobuf:"bytes,2,opt,name=sparse_merkle_proof,json=sparseMerkleProof,proto3" json:"sparse_merkle_proof,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
-	XXX_unrecognized     []byte             `json:"-"`
-	XXX_sizecache        int32              `json:"-"`
+	SparseMerkleProof    *types.SparseMerkleProof `protobuf:"bytes,2,opt,name=sparse_merkle_proof,json=sparseMerkleProof,proto3" json:"sparse_merkle_proof,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                 `json:"-"`
+	XXX_unrecognized     []byte                   `json:"-"`
+	XXX_sizecache        int32                    `json:"-"`
 }
 
 func (m *GetAccountStateWithProofByVersionResponse) Reset() {
 	*m = GetAccountStateWithProofByVersionResponse{}
 }
-func (m *GetAccountStateWithProofByVersionResponse) String() string { return proto.CompactTextString(m) }
-func (*GetAccountStateWithProofByVersionResponse) ProtoMessage()    {}
+func (m *GetAccountStateWithProofByVersionResponse) String() string {
+	return proto.CompactTextString(m)
+}
+func (*GetAccountStateWithProofByVersionResponse) ProtoMessage() {}
 func (*GetAccountStateWithProofByVersionResponse) Descriptor() ([]byte, []int) {
 	return fileDescriptor_0d2c4ccf1453ffdb, []int{5}
 }
@@ -310,14 +313,14 @@ func (m *GetAccountStateWithProofByVersionResponse) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_GetAccountStateWithProofByVersionResponse proto.InternalMessageInfo
 
-func (m *GetAccountStateWithProofByVersionResponse) GetAccountStateBlob() *AccountStateBlob {
+func (m *GetAccountStateWithProofByVersionResponse) GetAccountStateBlob() *types.AccountStateBlob {
 	if m != nil {
 		return m.AccountStateBlob
 	}
 	return nil
 }
 
-func (m *GetAccountStateWithProofByVersionResponse) GetSparseMerkleProof() *SparseMerkleProof {
+func (m *GetAccountStateWithProofByVersionResponse) GetSparseMerkleProof() *types.SparseMerkleProof {
 	if m != nil {
 		return m.SparseMerkleProof
 	}
@@ -399,7 +402,7 @@ type StartupInfo struct {
 	// The latest LedgerInfo. Note that at start up storage can have more
 	// transactions than the latest LedgerInfo indicates due to an incomplete
 	// start up sync.
-	LedgerInfo *LedgerInfo `protobuf:"bytes,1,opt,name=ledger_info,json=ledgerInfo,proto3" json:"ledger_info,omitempty"`
+	LedgerInfo *types.LedgerInfo `protobuf:"bytes,1,opt,name=ledger_info,json=ledgerInfo,proto3" json:"ledger_info,omitempty"`
 	// The latest version. All fields below are based on this version.
 	LatestVersion uint64 `protobuf:"varint,2,opt,name=latest_version,json=latestVersion,proto3" json:"latest_version,omitempty"`
 	// The latest account state root hash.
@@ -436,7 +439,7 @@ func (m *StartupInfo) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_StartupInfo proto.InternalMessageInfo
 
-func (m *StartupInfo) GetLedgerInfo() *LedgerInfo {
+func (m *StartupInfo) GetLedgerInfo() *types.LedgerInfo {
 	if m != nil {
 		return m.LedgerInfo
 	}
@@ -479,52 +482,52 @@ func init() {
 func init() { proto.RegisterFile("storage.proto", fileDescriptor_0d2c4ccf1453ffdb) }
 
 var fileDescriptor_0d2c4ccf1453ffdb = []byte{
-	// 714 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x55, 0xdd, 0x6e, 0xd3, 0x4a,
-	0x10, 0x96, 0x9b, 0xea, 0xf4, 0x9c, 0x49, 0xd2, 0xd3, 0x6e, 0x73, 0x4e, 0x8c, 0xf9, 0x69, 0xea,
-	0x0a, 0x29, 0xdc, 0x44, 0x22, 0x88, 0x6b, 0xa0, 0xa8, 0xb4, 0x48, 0x05, 0x81, 0x1d, 0xe8, 0x5d,
-	0xad, 0x4d, 0x32, 0x49, 0x2c, 0x52, 0xaf, 0xd9, 0x9d, 0x94, 0xb6, 0x8f, 0xc0, 0x83, 0xf0, 0x14,
-	0x3c, 0x0c, 0xaf, 0xc1, 0x1d, 0xf2, 0xee, 0xba, 0x71, 0x7e, 0x5a, 0xca, 0xe5, 0x7e, 0xf3, 0xed,
-	0xb7, 0x33, 0x5f, 0xbe, 0x71, 0xa0, 0xaa, 0x48, 0x48, 0x3e, 0xc4, 0x56, 0x2a, 0x05, 0x09, 0xb6,
-	0x66, 0x8f, 0x5e, 0x6d, 0x88, 0x14, 0x7d, 0x89, 0x69, 0x14, 0xa5, 0x52, 0x88, 0x81, 0x29, 0x7b,
-	0x9b, 0x63, 0xec, 0x0f, 0x51, 0x46, 0x71, 0x32, 0x10, 0x39, 0x44, 0x92, 0x27, 0x8a, 0xf7, 0x28,
-	0x16, 0x89, 0x85, 0x5c, 0xde, 0xeb, 0x89, 0x49, 0x42, 0x91, 0x22, 0x4e, 0x18, 0x75, 0xc7, 0xa2,
-	0x6b, 0x2b, 0xe5, 0x82, 0x98, 0xff, 0xc3, 0x81, 0x7a, 0xc8, 0xcf, 0xb0, 0x33, 0x15, 0x50, 0x01,
-	0x7e, 0x9e, 0xa0, 0x22, 0xf6, 0x1c, 0xd6, 0xe9, 0x3c, 0x51, 0x11, 0x89, 0xa8, 0x27, 0x4e, 0x4f,
-	0x63, 0x72, 0x9d, 0x46, 0xa9, 0x59, 0x6e, 0x7b, 0x2d, 0xba, 0x48, 0x51, 0xb5, 0x0a, 0x77, 0x3a,
-	0xe2, 0xa5, 0x66, 0x04, 0x95, 0xec, 0x46, 0x7e, 0x62, 0xbb, 0x50, 0x1d, 0xc4, 0x52, 0x51, 0x74,
-	0x86, 0x52, 0xc5, 0x22, 0x71, 0x57, 0x1a, 0x4e, 0x73, 0x35, 0xa8, 0x68, 0xf0, 0xa3, 0xc1, 0xd8,
-	0x09, 0xdc, 0x2d, 0x4c, 0x64, 0xe6, 0x55, 0xf1, 0x30, 0xe1, 0x34, 0x91, 0xa8, 0xdc, 0x52, 0xc3,
-	0x69, 0x96, 0xdb, 0xdb, 0xf6, 0xcd, 0x23, 0xcd, 0x7c, 0x9d, 0x0c, 0xc4, 0x71, 0x4c, 0xa3, 0xf0,
-	0x8a, 0x16, 0xb8, 0xe3, 0x6b, 0x2a, 0xbe, 0x07, 0xee, 0xe2, 0x84, 0x2a, 0x15, 0x89, 0x42, 0xff,
-	0x9b, 0x03, 0xff, 0x1f, 0x20, 0x2d, 0x9b, 0x7e, 0x37, 0xfb, 0x59, 0xb8, 0x9c, 0xf6, 0xee, 0x98,
-	0xde, 0x35, 0x98, 0xf7, 0x7e, 0x1f, 0xa0, 0xcb, 0xa9, 0x97, 0x35, 0x7c, 0x89, 0x76, 0xba, 0x7f,
-	0x34, 0x12, 0xc6, 0x97, 0xc8, 0x1e, 0xc2, 0xba, 0x1d, 0x2d, 0x17, 0x29, 0x69, 0x4a, 0xd5, 0xa0,
-	0xb9, 0xca, 0x0e, 0x54, 0x06, 0x98, 0xa9, 0xe0, 0x19, 0x26, 0xa4, 0xdc, 0xd5, 0x86, 0xd3, 0xfc,
-	0x3b, 0x28, 0x6b, 0x6c, 0x5f, 0x43, 0x7e, 0x0c, 0xf5, 0x85, 0x3e, 0xcd, 0x0c, 0xec, 0x2d, 0x6c,
-	0xd1, 0x79, 0x12, 0x8d, 0x63, 0x55, 0x0c, 0x8b, 0x6e, 0x77, 0xea, 0x5b, 0xe1, 0xe6, 0x51, 0xac,
-	0x28, 0xb3, 0xe8, 0x5d, 0x46, 0x0b, 0x36, 0xe8, 0x7c, 0x16, 0xf1, 0x4f, 0xa0, 0x79, 0x80, 0xf4,
-	0xc2, 0xc4, 0x27, 0xcc, 0xd2, 0x73, 0x55, 0xdb, 0xbb, 0xb0, 0x2d, 0xe7, 0x26, 0xb9, 0xb0, 0xc6,
-	0xfb, 0x7d, 0x89, 0x4a, 0xe9, 0xf7, 0x2a, 0x41, 0x7e, 0xcc, 0x2a, 0xb3, 0x3f, 0x7a, 0x7e, 0xf4,
-	0xbf, 0x3b, 0xf0, 0xe8, 0x16, 0x0f, 0xd8, 0xe9, 0xf6, 0x81, 0x2d, 0x26, 0xd9, 0x0e, 0x57, 0xb7,
-	0xc3, 0x15, 0xa5, 0xf6, 0xc6, 0xa2, 0x1b, 0x6c, 0xf0, 0x39, 0x84, 0x1d, 0xc2, 0x96, 0x4a, 0xb9,
-	0x54, 0x18, 0x9d, 0xa2, 0xfc, 0x34, 0x46, 0x6b, 0xd2, 0x8a, 0xd6, 0x71, 0xad, 0x4e, 0xa8, 0x19,
-	0x6f, 0x34, 0xc1, 0xb8, 0xb3, 0xa9, 0xe6, 0x21, 0xbf, 0x0e, 0xff, 0x1d, 0x60, 0xa6, 0x2c, 0x69,
-	0x92, 0x66, 0x71, 0xb3, 0x5e, 0xf8, 0x7b, 0x3a, 0x4a, 0x33, 0x05, 0x3b, 0x43, 0x13, 0x56, 0xb3,
-	0x68, 0xdb, 0xae, 0x6b, 0xad, 0x7c, 0xdd, 0x8b, 0x5c, 0xcd, 0xc8, 0xd6, 0xb1, 0x5c, 0x40, 0x59,
-	0x1b, 0xca, 0x85, 0xdd, 0xb0, 0x02, 0x9b, 0x0b, 0xbb, 0x10, 0xc0, 0x34, 0xfd, 0x3a, 0x74, 0x9c,
-	0x70, 0x61, 0xeb, 0xaa, 0x06, 0xcd, 0x43, 0xf7, 0x14, 0xea, 0xb3, 0xc6, 0x4a, 0x21, 0x28, 0x1a,
-	0x71, 0x35, 0xd2, 0x21, 0xad, 0x04, 0xb5, 0xa2, 0x89, 0x81, 0x10, 0x74, 0xc8, 0xd5, 0x88, 0x3d,
-	0x83, 0x7b, 0xb6, 0xa3, 0x81, 0x14, 0x97, 0x98, 0x44, 0x6a, 0xd2, 0x25, 0x89, 0xa8, 0x6f, 0x62,
-	0x96, 0xdd, 0x52, 0xb3, 0x12, 0xdc, 0x31, 0x9c, 0x57, 0x9a, 0x12, 0x1a, 0xc6, 0xa1, 0x26, 0xb4,
-	0x7f, 0x96, 0x60, 0x2d, 0x34, 0x06, 0xb0, 0x63, 0xd8, 0x98, 0x5f, 0x4d, 0xd6, 0x98, 0xda, 0xb3,
-	0xfc, 0xbb, 0xe4, 0xed, 0xdc, 0xc0, 0xb0, 0x8e, 0x47, 0x50, 0xfb, 0x90, 0xf6, 0x39, 0x61, 0x47,
-	0x1c, 0xe9, 0xa9, 0x8d, 0x57, 0xcc, 0xb7, 0xd6, 0x2d, 0x2b, 0xe6, 0xf2, 0xbb, 0x37, 0x72, 0xec,
-	0x03, 0x1d, 0xf8, 0x77, 0x6e, 0x1f, 0xd9, 0xf6, 0x55, 0x5b, 0xcb, 0xbf, 0x28, 0x5e, 0xe3, 0x7a,
-	0x82, 0x55, 0xfd, 0xea, 0xc0, 0xce, 0x6f, 0x57, 0x83, 0x3d, 0x2e, 0xea, 0xdc, 0x6a, 0x4f, 0xbd,
-	0xf6, 0x9f, 0x5c, 0xb1, 0xcd, 0xbc, 0x87, 0xf5, 0xd9, 0x3c, 0xb3, 0x07, 0x45, 0x95, 0xc5, 0x0d,
-	0xf0, 0xb6, 0xaf, 0xad, 0x1b, 0xc9, 0xee, 0x5f, 0xfa, 0x4f, 0xe7, 0xc9, 0xaf, 0x00, 0x00, 0x00,
-	0xff, 0xff, 0x1a, 0x8f, 0xbb, 0x2b, 0xf1, 0x06, 0x00, 0x00,
+	// 707 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x55, 0xdd, 0x4e, 0x13, 0x41,
+	0x14, 0xce, 0x52, 0x22, 0x7a, 0xda, 0x22, 0x0c, 0x95, 0xae, 0xeb, 0x0f, 0x65, 0x89, 0x49, 0xbd,
+	0x69, 0x62, 0x8d, 0xd7, 0x2a, 0x06, 0xc1, 0x04, 0x8d, 0x6e, 0xab, 0xdc, 0xb1, 0x99, 0xb6, 0xa7,
+	0xed, 0xc6, 0xb2, 0xb3, 0xce, 0x9c, 0x22, 0xf0, 0x08, 0x3e, 0x88, 0x4f, 0xe1, 0xc3, 0xf8, 0x1a,
+	0xde, 0x99, 0x9d, 0x99, 0xa5, 0xdb, 0x1f, 0x10, 0x2f, 0xe7, 0x3b, 0xdf, 0x7c, 0x73, 0xbe, 0xaf,
+	0xe7, 0x6c, 0xa1, 0xac, 0x48, 0x48, 0x3e, 0xc0, 0x46, 0x22, 0x05, 0x09, 0xb6, 0x62, 0x8f, 0x5e,
+	0x65, 0x80, 0x14, 0x7e, 0x8f, 0x68, 0x18, 0x26, 0x52, 0x88, 0xbe, 0x29, 0x7b, 0xeb, 0x23, 0xec,
+	0x0d, 0x50, 0x86, 0x51, 0xdc, 0x17, 0x19, 0x44, 0x92, 0xc7, 0x8a, 0x77, 0x29, 0x12, 0xb1, 0x85,
+	0x5c, 0xde, 0xed, 0x8a, 0x71, 0x4c, 0xa1, 0x22, 0x4e, 0x18, 0x76, 0x46, 0xa2, 0x63, 0x2b, 0xc5,
+	0x9c, 0x98, 0xff, 0xdb, 0x81, 0x6a, 0x8b, 0x9f, 0x62, 0x7b, 0x22, 0xa0, 0x02, 0xfc, 0x36, 0x46,
+	0x45, 0xec, 0x15, 0xac, 0xd2, 0x59, 0xac, 0x42, 0x12, 0x61, 0x57, 0x9c, 0x9c, 0x44, 0xe4, 0x3a,
+	0xb5, 0x42, 0xbd, 0xd8, 0xf4, 0x1a, 0x74, 0x9e, 0xa0, 0x6a, 0xe4, 0xee, 0xb4, 0xc5, 0x1b, 0xcd,
+	0x08, 0x4a, 0xe9, 0x8d, 0xec, 0xc4, 0x76, 0xa0, 0xdc, 0x8f, 0xa4, 0xa2, 0xf0, 0x14, 0xa5, 0x8a,
+	0x44, 0xec, 0x2e, 0xd5, 0x9c, 0xfa, 0x72, 0x50, 0xd2, 0xe0, 0x17, 0x83, 0xb1, 0x63, 0x78, 0x90,
+	0x73, 0x64, 0xfc, 0xaa, 0x68, 0x10, 0x73, 0x1a, 0x4b, 0x54, 0x6e, 0xa1, 0xe6, 0xd4, 0x8b, 0xcd,
+	0x2d, 0xfb, 0xe6, 0xa1, 0x66, 0xbe, 0x8b, 0xfb, 0xe2, 0x28, 0xa2, 0x61, 0xeb, 0x92, 0x16, 0xb8,
+	0xa3, 0x2b, 0x2a, 0xbe, 0x07, 0xee, 0xbc, 0x43, 0x95, 0x88, 0x58, 0xa1, 0xff, 0xd3, 0x81, 0xcd,
+	0x7d, 0xa4, 0x45, 0xee, 0x77, 0xd2, 0x9f, 0x85, 0xcb, 0x49, 0xef, 0x8e, 0xe9, 0x5d, 0x83, 0x59,
+	0xef, 0x8f, 0x00, 0x3a, 0x9c, 0xba, 0x69, 0xc3, 0x17, 0x68, 0xdd, 0xdd, 0xd1, 0x48, 0x2b, 0xba,
+	0x40, 0xf6, 0x04, 0x56, 0xad, 0xb5, 0x4c, 0xa4, 0xa0, 0x29, 0x65, 0x83, 0x66, 0x2a, 0xdb, 0x50,
+	0xea, 0x63, 0xaa, 0x82, 0xa7, 0x18, 0x93, 0x72, 0x97, 0x6b, 0x4e, 0xfd, 0x76, 0x50, 0xd4, 0xd8,
+	0x9e, 0x86, 0xfc, 0x08, 0xaa, 0x73, 0x7d, 0x1a, 0x0f, 0xec, 0x03, 0x6c, 0xd0, 0x59, 0x1c, 0x8e,
+	0x22, 0x95, 0x1f, 0x16, 0xd7, 0x99, 0xca, 0x2d, 0x77, 0xf3, 0x30, 0x52, 0x94, 0x46, 0xf4, 0x31,
+	0xa5, 0x05, 0x6b, 0x74, 0x36, 0x8d, 0xf8, 0xc7, 0x50, 0xdf, 0x47, 0x7a, 0x6d, 0xc6, 0xa7, 0x45,
+	0x9c, 0xf0, 0xb2, 0xb6, 0x7b, 0x6e, 0x5b, 0xce, 0x42, 0x72, 0x61, 0x85, 0xf7, 0x7a, 0x12, 0x95,
+	0xd2, 0xef, 0x95, 0x82, 0xec, 0x98, 0x56, 0xa6, 0x7f, 0xf4, 0xec, 0xe8, 0xff, 0x72, 0xe0, 0xe9,
+	0x0d, 0x1e, 0xb0, 0xee, 0xf6, 0x80, 0xcd, 0x4f, 0xb2, 0x35, 0x57, 0xb5, 0xe6, 0xf2, 0x52, 0xbb,
+	0x23, 0xd1, 0x09, 0xd6, 0xf8, 0x0c, 0xc2, 0x0e, 0x60, 0x43, 0x25, 0x5c, 0x2a, 0x0c, 0x4f, 0x50,
+	0x7e, 0x1d, 0xa1, 0x0d, 0x69, 0x49, 0xeb, 0xb8, 0x56, 0xa7, 0xa5, 0x19, 0xef, 0x35, 0xc1, 0xa4,
+	0xb3, 0xae, 0x66, 0x21, 0xbf, 0x0a, 0xf7, 0xf6, 0x31, 0x55, 0x96, 0x34, 0x4e, 0xd2, 0x71, 0xb3,
+	0x59, 0xf8, 0xbb, 0xb0, 0x39, 0x5b, 0xb0, 0x1e, 0xea, 0xb0, 0x9c, 0x8e, 0xb6, 0xed, 0xba, 0xd2,
+	0xc8, 0xd6, 0x3d, 0xcf, 0xd5, 0x8c, 0x74, 0x1d, 0x8b, 0x39, 0x94, 0x35, 0xa1, 0x98, 0xdb, 0x0d,
+	0x2b, 0xb0, 0x3e, 0xb7, 0x0b, 0x01, 0x4c, 0xa6, 0x5f, 0x0f, 0x1d, 0x27, 0x9c, 0xdb, 0xba, 0xb2,
+	0x41, 0xb3, 0xa1, 0x7b, 0x01, 0xd5, 0xe9, 0x60, 0xa5, 0x10, 0x14, 0x0e, 0xb9, 0x1a, 0xea, 0x21,
+	0x2d, 0x05, 0x95, 0x7c, 0x88, 0x81, 0x10, 0x74, 0xc0, 0xd5, 0x90, 0xbd, 0x84, 0x87, 0xb6, 0xa3,
+	0xbe, 0x14, 0x17, 0x18, 0x87, 0x6a, 0xdc, 0x21, 0x89, 0xa8, 0x6f, 0x62, 0x3a, 0xbb, 0x85, 0x7a,
+	0x29, 0xb8, 0x6f, 0x38, 0x6f, 0x35, 0xa5, 0x65, 0x18, 0x07, 0x9a, 0xd0, 0xfc, 0x53, 0x80, 0x95,
+	0x96, 0x09, 0x80, 0x1d, 0xc1, 0xda, 0xec, 0x6a, 0xb2, 0xda, 0x24, 0x9e, 0xc5, 0xdf, 0x25, 0x6f,
+	0xfb, 0x1a, 0x86, 0x4d, 0x3c, 0x84, 0xca, 0xe7, 0xa4, 0xc7, 0x09, 0xdb, 0xe2, 0x50, 0xbb, 0x36,
+	0x59, 0x31, 0xdf, 0x46, 0xb7, 0xa8, 0x98, 0xc9, 0xef, 0x5c, 0xcb, 0xb1, 0x0f, 0xb4, 0xe1, 0xee,
+	0xcc, 0x3e, 0xb2, 0xad, 0xcb, 0xb6, 0x16, 0x7f, 0x51, 0xbc, 0xda, 0xd5, 0x04, 0xab, 0xfa, 0xc3,
+	0x81, 0xed, 0x7f, 0xae, 0x06, 0x7b, 0x96, 0xd7, 0xb9, 0xd1, 0x9e, 0x7a, 0xcd, 0xff, 0xb9, 0x62,
+	0x9b, 0xf9, 0x04, 0xab, 0xd3, 0xf3, 0xcc, 0x1e, 0xe7, 0x55, 0xe6, 0x37, 0xc0, 0xdb, 0xba, 0xb2,
+	0x6e, 0x24, 0x3b, 0xb7, 0xf4, 0x9f, 0xce, 0xf3, 0xbf, 0x03, 0x00, 0x1a, 0x8f, 0xbb, 0x2b, 0xf1,
+	0x06, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -546,7 +549,7 @@ type StorageClient interface {
 	// knows and trusts a ledger info at version v, it should pass v in as the
 	// client_known_version and we will return the latest ledger info together
 	// with the proof that it derives from v.
-	UpdateToLatestLedger(ctx context.Context, in *UpdateToLatestLedgerRequest, opts ...grpc.CallOption) (*UpdateToLatestLedgerResponse, error)
+	UpdateToLatestLedger(ctx context.Context, in *types.UpdateToLatestLedgerRequest, opts ...grpc.CallOption) (*types.UpdateToLatestLedgerResponse, error)
 	// When we receive a request from a peer validator asking a list of
 	// transactions for state synchronization, this API can be used to serve the
 	// request. Note that the peer should specify a ledger version and all proofs
@@ -574,8 +577,8 @@ func (c *storageClient) SaveTransactions(ctx context.Context, in *SaveTransactio
 	return out, nil
 }
 
-func (c *storageClient) UpdateToLatestLedger(ctx context.Context, in *UpdateToLatestLedgerRequest, opts ...grpc.CallOption) (*UpdateToLatestLedgerResponse, error) {
-	out := new(UpdateToLatestLedgerResponse)
+func (c *storageClient) UpdateToLatestLedger(ctx context.Context, in *types.UpdateToLatestLedgerRequest, opts ...grpc.CallOption) (*types.UpdateToLatestLedgerResponse, error) {
+	out := new(types.UpdateToLatestLedgerResponse)
 	err := c.cc.Invoke(ctx, "/storage.Storage/UpdateToLatestLedger", in, out, opts...)
 	if err != nil {
 		return nil, err
@@ -619,7 +622,7 @@ type StorageServer interface {
 	// knows and trusts a ledger info at version v, it should pass v in as the
 	// client_known_version and we will return the latest ledger info together
 	// with the proof that it derives from v.
-	UpdateToLatestLedger(context.Context, *UpdateToLatestLedgerRequest) (*UpdateToLatestLedgerResponse, error)
+	UpdateToLatestLedger(context.Context, *types.UpdateToLatestLedgerRequest) (*types.UpdateToLatestLedgerResponse, error)
 	// When we receive a request from a peer validator asking a list of
 	// transactions for state synchronization, this API can be used to serve the
 	// request. Note that the peer should specify a ledger version and all proofs
@@ -637,7 +640,7 @@ type UnimplementedStorageServer struct {
 func (*UnimplementedStorageServer) SaveTransactions(ctx context.Context, req *SaveTransactionsRequest) (*SaveTransactionsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SaveTransactions not implemented")
 }
-func (*UnimplementedStorageServer) UpdateToLatestLedger(ctx context.Context, req *UpdateToLatestLedgerRequest) (*UpdateToLatestLedgerResponse, error) {
+func (*UnimplementedStorageServer) UpdateToLatestLedger(ctx context.Context, req *types.UpdateToLatestLedgerRequest) (*types.UpdateToLatestLedgerResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method UpdateToLatestLedger not implemented")
 }
 func (*UnimplementedStorageServer) GetTransactions(ctx context.Context, req *GetTransactionsRequest) (*GetTransactionsResponse, error) {
@@ -673,7 +676,7 @@ func _Storage_SaveTransactions_Handler(srv interface{}, ctx context.Context, dec
 }
 
 func _Storage_UpdateToLatestLedger_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(UpdateToLatestLedgerRequest)
+	in := new(types.UpdateToLatestLedgerRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
@@ -685,7 +688,7 @@ func _Storage_UpdateToLatestLedger_Handler(srv interface{}, ctx context.Context,
 		FullMethod: "/storage.Storage/UpdateToLatestLedger",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(StorageServer).UpdateToLatestLedger(ctx, req.(*UpdateToLatestLedgerRequest))
+		return srv.(StorageServer).UpdateToLatestLedger(ctx, req.(*types.UpdateToLatestLedgerRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
+	"io.librablock.go/controllers"
+	"io.librablock.go/proto/admission_control"
+	"io.librablock.go/rpcproxy"
+)
+
+func usage() {
+	fmt.Println("usage: rpc_proxy record | replay")
+	os.Exit(2)
+}
+
+func main() {
+	listen := os.Getenv("LIBRA_PROXY_LISTEN")
+	if listen == "" {
+		listen = "127.0.0.1:8000"
+	}
+	dir := os.Getenv("LIBRA_PROXY_FIXTURES")
+	if dir == "" {
+		dir = "fixtures"
+	}
+
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	var proxy *rpcproxy.Proxy
+
+	switch os.Args[1] {
+	case "record":
+		upstream := controllers.DefaultAddress
+		if address := controllers.AddressFromEnv(); address != nil {
+			upstream = *address
+		}
+
+		conn, err := grpc.Dial(upstream, controllers.RPCOptionsFromEnv().DialOptions()...)
+		if err != nil {
+			fmt.Printf("Failed to dial %s: %s\n", upstream, err.Error())
+			os.Exit(1)
+		}
+		defer conn.Close()
+
+		proxy = rpcproxy.NewRecordingProxy(dir, admission_control.NewAdmissionControlClient(conn))
+		fmt.Printf("Recording %s into %s\n", upstream, dir)
+	case "replay":
+		proxy = rpcproxy.NewReplayProxy(dir)
+		fmt.Printf("Replaying %s\n", dir)
+	default:
+		usage()
+	}
+
+	lis, err := net.Listen("tcp", listen)
+	if err != nil {
+		fmt.Printf("Failed to listen on %s: %s\n", listen, err.Error())
+		os.Exit(1)
+	}
+
+	// accept the keepalive pings clients are allowed to send, see
+	// controllers.MinKeepaliveTime
+	s := grpc.NewServer(grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+		MinTime: controllers.MinKeepaliveTime,
+	}))
+	admission_control.RegisterAdmissionControlServer(s, proxy)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		s.GracefulStop()
+	}()
+
+	fmt.Printf("Listening on %s\n", listen)
+	if err := s.Serve(lis); err != nil {
+		fmt.Printf("Failed to serve: %s\n", err.Error())
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,189 @@
+package rpcproxy
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"golang.org/x/crypto/sha3"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"io.librablock.go/proto/admission_control"
+	"io.librablock.go/proto/types"
+)
+
+const (
+	updateToLatestLedgerMethod = "UpdateToLatestLedger"
+	submitTransactionMethod    = "SubmitTransaction"
+)
+
+// Recording is the fixture file of one call, named after the method and the
+// hash of its serialized request.
+type Recording struct {
+	Method   string          `json:"method"`
+	Request  json.RawMessage `json:"request"`
+	Response json.RawMessage `json:"response,omitempty"`
+	Code     codes.Code      `json:"code,omitempty"`
+	Message  string          `json:"message,omitempty"`
+}
+
+// Proxy is an AdmissionControl server that either forwards calls to a node
+// and records them into Dir, or answers them from earlier recordings. Calls
+// with identical requests share one recording, the latest one wins. The
+// client known version of UpdateToLatestLedger is left out of the comparison,
+// since it changes with every call of a verifying client.
+type Proxy struct {
+	admission_control.UnimplementedAdmissionControlServer
+
+	Dir string
+
+	upstream admission_control.AdmissionControlClient
+}
+
+// NewRecordingProxy forwards every call to upstream and records it.
+func NewRecordingProxy(dir string, upstream admission_control.AdmissionControlClient) *Proxy {
+	return &Proxy{Dir: dir, upstream: upstream}
+}
+
+// NewReplayProxy answers calls from the recordings in dir only, and fails
+// with NotFound for requests that were never recorded.
+func NewReplayProxy(dir string) *Proxy {
+	return &Proxy{Dir: dir}
+}
+
+func (p *Proxy) path(method string, req proto.Message) (string, error) {
+	buf := proto.NewBuffer(nil)
+	buf.SetDeterministic(true)
+	if err := buf.Marshal(req); err != nil {
+		return "", err
+	}
+
+	hash := sha3.Sum256(append([]byte(method+":"), buf.Bytes()...))
+	return filepath.Join(p.Dir, method+"-"+hex.EncodeToString(hash[:])+".json"), nil
+}
+
+// call answers req, recorded under the hash of key.
+func (p *Proxy) call(method string, key proto.Message, req proto.Message, resp proto.Message, forward func() (proto.Message, error)) error {
+	path, err := p.path(method, key)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if p.upstream == nil {
+		return p.replay(path, resp)
+	}
+
+	result, callErr := forward()
+
+	// a call abandoned by the client says nothing about the node
+	if code := status.Code(callErr); code == codes.Canceled || code == codes.DeadlineExceeded {
+		return callErr
+	}
+
+	if err := p.record(path, method, req, result, callErr); err != nil {
+		return status.Errorf(codes.Internal, "failed to record %s: %v", method, err)
+	}
+	if callErr != nil {
+		return callErr
+	}
+
+	proto.Merge(resp, result)
+	return nil
+}
+
+func (p *Proxy) record(path string, method string, req proto.Message, resp proto.Message, callErr error) error {
+	m := jsonpb.Marshaler{Indent: "  "}
+
+	request, err := m.MarshalToString(req)
+	if err != nil {
+		return err
+	}
+
+	rec := Recording{Method: method, Request: json.RawMessage(request)}
+	if callErr != nil {
+		s := status.Convert(callErr)
+		rec.Code = s.Code()
+		rec.Message = s.Message()
+	} else {
+		response, err := m.MarshalToString(resp)
+		if err != nil {
+			return err
+		}
+		rec.Response = json.RawMessage(response)
+	}
+
+	data, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(p.Dir, 0755); err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (p *Proxy) replay(path string, resp proto.Message) error {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return status.Errorf(codes.NotFound, "no recording %s", filepath.Base(path))
+	}
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	rec := Recording{}
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return status.Errorf(codes.Internal, "bad recording %s: %v", filepath.Base(path), err)
+	}
+
+	if rec.Code != codes.OK {
+		return status.Error(rec.Code, rec.Message)
+	}
+
+	if err := jsonpb.Unmarshal(bytes.NewReader(rec.Response), resp); err != nil {
+		return status.Errorf(codes.Internal, "bad recording %s: %v", filepath.Base(path), err)
+	}
+	return nil
+}
+
+func (p *Proxy) UpdateToLatestLedger(ctx context.Context, req *types.UpdateToLatestLedgerRequest) (*types.UpdateToLatestLedgerResponse, error) {
+	resp := types.UpdateToLatestLedgerResponse{}
+
+	key := proto.Clone(req).(*types.UpdateToLatestLedgerRequest)
+	key.ClientKnownVersion = 0
+
+	err := p.call(updateToLatestLedgerMethod, key, req, &resp, func() (proto.Message, error) {
+		return p.upstream.UpdateToLatestLedger(ctx, req)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+func (p *Proxy) SubmitTransaction(ctx context.Context, req *admission_control.SubmitTransactionRequest) (*admission_control.SubmitTransactionResponse, error) {
+	resp := admission_control.SubmitTransactionResponse{}
+
+	err := p.call(submitTransactionMethod, req, req, &resp, func() (proto.Message, error) {
+		return p.upstream.SubmitTransaction(ctx, req)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
@@ -0,0 +1,128 @@
+package rpcproxy
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"io.librablock.go/proto/admission_control"
+	"io.librablock.go/proto/types"
+	"io.librablock.go/testutil"
+)
+
+func serve(t *testing.T, server admission_control.AdmissionControlServer) (admission_control.AdmissionControlClient, func()) {
+	address, stop, err := testutil.Serve(func(s *grpc.Server) {
+		admission_control.RegisterAdmissionControlServer(s, server)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conn, err := grpc.Dial(address, grpc.WithInsecure())
+	if err != nil {
+		stop()
+		t.Fatal(err)
+	}
+
+	return admission_control.NewAdmissionControlClient(conn), func() {
+		conn.Close()
+		stop()
+	}
+}
+
+func latestRequest(knownVersion uint64) *types.UpdateToLatestLedgerRequest {
+	return &types.UpdateToLatestLedgerRequest{
+		ClientKnownVersion: knownVersion,
+		RequestedItems: []*types.RequestItem{{
+			RequestedItems: &types.RequestItem_GetAccountStateRequest{
+				GetAccountStateRequest: &types.GetAccountStateRequest{Address: testutil.Alice},
+			},
+		}},
+	}
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rpcproxy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ac := testutil.NewFakeAdmissionControl()
+	if err := ac.CommitPayments(testutil.Alice, testutil.Bob, 3); err != nil {
+		t.Fatal(err)
+	}
+	node, stopNode := serve(t, ac)
+	defer stopNode()
+
+	recorder, stopRecorder := serve(t, NewRecordingProxy(dir, node))
+	defer stopRecorder()
+
+	ctx := context.Background()
+	recorded, err := recorder.UpdateToLatestLedger(ctx, latestRequest(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	submitted, err := recorder.SubmitTransaction(ctx, &admission_control.SubmitTransactionRequest{SignedTxn: &types.SignedTransaction{RawTxnBytes: []byte("txn")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	replayer, stopReplayer := serve(t, NewReplayProxy(dir))
+	defer stopReplayer()
+
+	// a client that has since learned a newer version gets the same answer
+	replayed, err := replayer.UpdateToLatestLedger(ctx, latestRequest(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayed.String() != recorded.String() {
+		t.Errorf("replayed %v, recorded %v", replayed, recorded)
+	}
+
+	replayedSubmit, err := replayer.SubmitTransaction(ctx, &admission_control.SubmitTransactionRequest{SignedTxn: &types.SignedTransaction{RawTxnBytes: []byte("txn")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayedSubmit.String() != submitted.String() {
+		t.Errorf("replayed %v, recorded %v", replayedSubmit, submitted)
+	}
+
+	other := latestRequest(0)
+	other.RequestedItems[0].GetGetAccountStateRequest().Address = bytes.Repeat([]byte{0xbb}, 32)
+	if _, err := replayer.UpdateToLatestLedger(ctx, other); status.Code(err) != codes.NotFound {
+		t.Errorf("got %v for a request never recorded, want NotFound", err)
+	}
+}
+
+func TestRecordError(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rpcproxy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// an empty fake ledger answers Unavailable
+	node, stopNode := serve(t, testutil.NewFakeAdmissionControl())
+	defer stopNode()
+
+	recorder, stopRecorder := serve(t, NewRecordingProxy(dir, node))
+	defer stopRecorder()
+
+	if _, err := recorder.UpdateToLatestLedger(context.Background(), latestRequest(0)); status.Code(err) != codes.Unavailable {
+		t.Fatalf("got %v, want Unavailable", err)
+	}
+
+	replayer, stopReplayer := serve(t, NewReplayProxy(dir))
+	defer stopReplayer()
+
+	if _, err := replayer.UpdateToLatestLedger(context.Background(), latestRequest(0)); status.Code(err) != codes.Unavailable {
+		t.Errorf("replayed %v, want Unavailable", err)
+	}
+}
@@ -0,0 +1,450 @@
+package testutil
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"golang.org/x/crypto/ed25519"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"io.librablock.go/hasher"
+	"io.librablock.go/keys"
+	"io.librablock.go/lcs"
+	"io.librablock.go/proto/admission_control"
+	"io.librablock.go/proto/types"
+	"io.librablock.go/verifier"
+)
+
+// FakeTransaction is one scripted ledger entry.
+type FakeTransaction struct {
+	Transaction *types.SignedTransaction
+	Events      []*types.Event
+	GasUsed     uint64
+	// Accounts replaces the state blob of every address (raw bytes used as
+	// string keys) it lists; a nil blob deletes the account.
+	Accounts map[string][]byte
+}
+
+type fakeVersion struct {
+	transaction *types.SignedTransaction
+	events      []*types.Event
+	info        *types.TransactionInfo
+	state       []sparseMerkleLeaf
+	accounts    map[string][]byte
+}
+
+// FakeAdmissionControl is an in-memory AdmissionControl node serving a
+// scripted ledger with real accumulator and sparse merkle proofs, so that
+// LibraRPC can verify everything it receives. Ledger infos are signed by
+// Validators when there are any.
+type FakeAdmissionControl struct {
+	admission_control.UnimplementedAdmissionControlServer
+
+	Validators []*keys.Key
+	// SubmitResponse, when set, answers every SubmitTransaction; otherwise
+	// submissions are accepted.
+	SubmitResponse *admission_control.SubmitTransactionResponse
+
+	mu        sync.Mutex
+	versions  []fakeVersion
+	submitted []*types.SignedTransaction
+}
+
+func NewFakeAdmissionControl() *FakeAdmissionControl {
+	return &FakeAdmissionControl{}
+}
+
+// ServeFakeAdmissionControl serves ac on a free local port, see Serve.
+func ServeFakeAdmissionControl(ac *FakeAdmissionControl) (string, func(), error) {
+	return Serve(func(s *grpc.Server) {
+		admission_control.RegisterAdmissionControlServer(s, ac)
+	})
+}
+
+// AccountBlob serializes resource as the only resource of an account.
+func AccountBlob(resource *lcs.AccountResource) []byte {
+	return lcs.EncodeAccountStateBlob(map[string][]byte{
+		string(lcs.AccountResourcePath): resource.Encode(),
+	})
+}
+
+// PaymentEvent builds a sent or received payment event of address, with
+// path being lcs.SentEventsPath or lcs.ReceivedEventsPath.
+func PaymentEvent(address []byte, path string, sequenceNumber uint64, amount uint64, counterparty []byte) *types.Event {
+	return &types.Event{
+		Key:            lcs.AccountEventKey(address, path),
+		SequenceNumber: sequenceNumber,
+		EventData:      lcs.NewEncoder().EncodeU64(amount).EncodeBytes(counterparty).Result(),
+	}
+}
+
+// PaymentTransaction builds a signed transaction of sender paying amount
+// to receiver. Its program and signature are placeholders.
+func PaymentTransaction(sender []byte, sequenceNumber uint64, receiver []byte, amount uint64) (*types.SignedTransaction, error) {
+	raw := types.RawTransaction{
+		SenderAccount:  sender,
+		SequenceNumber: sequenceNumber,
+		Payload: &types.RawTransaction_Program{
+			Program: &types.Program{
+				Code: []byte("script"),
+				Arguments: []*types.TransactionArgument{
+					{Type: types.TransactionArgument_ADDRESS, Data: receiver},
+					{Type: types.TransactionArgument_U64, Data: lcs.NewEncoder().EncodeU64(amount).Result()},
+				},
+			},
+		},
+		MaxGasAmount:   100,
+		ExpirationTime: 1,
+	}
+
+	rawTxnBytes, err := proto.Marshal(&raw)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.SignedTransaction{
+		RawTxnBytes:     rawTxnBytes,
+		SenderPublicKey: bytes.Repeat([]byte{0x01}, ed25519.PublicKeySize),
+		SenderSignature: bytes.Repeat([]byte{0x02}, ed25519.SignatureSize),
+	}, nil
+}
+
+// CommitPayments commits count payments from sender to receiver on a ledger
+// of no other transactions. Payment i has sequence number i, moves 10+i and
+// emits a sent and a received payment event.
+func (ac *FakeAdmissionControl) CommitPayments(sender []byte, receiver []byte, count int) error {
+	for i := 0; i < count; i++ {
+		txn, err := PaymentTransaction(sender, uint64(i), receiver, uint64(10+i))
+		if err != nil {
+			return err
+		}
+
+		_, err = ac.Commit(FakeTransaction{
+			Transaction: txn,
+			Events: []*types.Event{
+				PaymentEvent(sender, lcs.SentEventsPath, uint64(i), uint64(10+i), receiver),
+				PaymentEvent(receiver, lcs.ReceivedEventsPath, uint64(i), uint64(10+i), sender),
+			},
+			GasUsed: 5,
+			Accounts: map[string][]byte{
+				string(sender):   AccountBlob(&lcs.AccountResource{SentEventsCount: uint64(i + 1), SequenceNumber: uint64(i + 1)}),
+				string(receiver): AccountBlob(&lcs.AccountResource{Balance: uint64(10 + i), ReceivedEventsCount: uint64(i + 1)}),
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Commit appends txn to the ledger and returns its version.
+func (ac *FakeAdmissionControl) Commit(txn FakeTransaction) (uint64, error) {
+	data, err := proto.Marshal(txn.Transaction)
+	if err != nil {
+		return 0, err
+	}
+
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+
+	accounts := make(map[string][]byte)
+	if len(ac.versions) > 0 {
+		for address, blob := range ac.versions[len(ac.versions)-1].accounts {
+			accounts[address] = blob
+		}
+	}
+	for address, blob := range txn.Accounts {
+		if blob == nil {
+			delete(accounts, address)
+		} else {
+			accounts[address] = blob
+		}
+	}
+
+	eventHashes := make([][]byte, len(txn.Events))
+	for i, event := range txn.Events {
+		eventHashes[i] = hasher.ContractEvent(event)
+	}
+
+	state := sparseMerkleLeaves(accounts)
+
+	ac.versions = append(ac.versions, fakeVersion{
+		transaction: txn.Transaction,
+		events:      txn.Events,
+		info: &types.TransactionInfo{
+			SignedTransactionHash: hasher.SignedTransaction(data),
+			StateRootHash:         sparseMerkleRoot(state),
+			EventRootHash:         accumulatorRoot(eventHashes, hasher.EventAccumulatorNode),
+			GasUsed:               txn.GasUsed,
+		},
+		state:    state,
+		accounts: accounts,
+	})
+
+	return uint64(len(ac.versions) - 1), nil
+}
+
+// Submitted returns the transactions received through SubmitTransaction.
+func (ac *FakeAdmissionControl) Submitted() []*types.SignedTransaction {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+
+	return append([]*types.SignedTransaction{}, ac.submitted...)
+}
+
+// TrustedState returns a trusted state for the validators of the fake,
+// which is not persisted anywhere.
+func (ac *FakeAdmissionControl) TrustedState() *verifier.TrustedState {
+	state := verifier.TrustedState{}
+	for _, key := range ac.Validators {
+		state.Validators = append(state.Validators, verifier.Validator{
+			AccountAddress:     key.AddressHex(),
+			ConsensusPublicKey: hex.EncodeToString(key.PublicKey),
+		})
+	}
+	return &state
+}
+
+func (ac *FakeAdmissionControl) SubmitTransaction(ctx context.Context, req *admission_control.SubmitTransactionRequest) (*admission_control.SubmitTransactionResponse, error) {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+
+	ac.submitted = append(ac.submitted, req.SignedTxn)
+
+	if ac.SubmitResponse != nil {
+		return ac.SubmitResponse, nil
+	}
+
+	return &admission_control.SubmitTransactionResponse{
+		Status: &admission_control.SubmitTransactionResponse_AcStatus{
+			AcStatus: &admission_control.AdmissionControlStatus{Code: admission_control.AdmissionControlStatusCode_Accepted},
+		},
+	}, nil
+}
+
+func (ac *FakeAdmissionControl) UpdateToLatestLedger(ctx context.Context, req *types.UpdateToLatestLedgerRequest) (*types.UpdateToLatestLedgerResponse, error) {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+
+	if len(ac.versions) == 0 {
+		return nil, status.Error(codes.Unavailable, "empty ledger")
+	}
+
+	resp := types.UpdateToLatestLedgerResponse{LedgerInfoWithSigs: ac.ledgerInfo()}
+
+	for _, item := range req.RequestedItems {
+		var respItem types.ResponseItem
+
+		switch r := item.RequestedItems.(type) {
+		case *types.RequestItem_GetAccountStateRequest:
+			respItem.ResponseItems = &types.ResponseItem_GetAccountStateResponse{
+				GetAccountStateResponse: &types.GetAccountStateResponse{
+					AccountStateWithProof: ac.accountState(r.GetAccountStateRequest.Address),
+				},
+			}
+		case *types.RequestItem_GetAccountTransactionBySequenceNumberRequest:
+			respItem.ResponseItems = &types.ResponseItem_GetAccountTransactionBySequenceNumberResponse{
+				GetAccountTransactionBySequenceNumberResponse: ac.accountTransaction(r.GetAccountTransactionBySequenceNumberRequest),
+			}
+		case *types.RequestItem_GetEventsByEventAccessPathRequest:
+			respItem.ResponseItems = &types.ResponseItem_GetEventsByEventAccessPathResponse{
+				GetEventsByEventAccessPathResponse: ac.events(r.GetEventsByEventAccessPathRequest),
+			}
+		case *types.RequestItem_GetTransactionsRequest:
+			respItem.ResponseItems = &types.ResponseItem_GetTransactionsResponse{
+				GetTransactionsResponse: &types.GetTransactionsResponse{
+					TxnListWithProof: ac.transactions(r.GetTransactionsRequest),
+				},
+			}
+		default:
+			return nil, status.Errorf(codes.InvalidArgument, "unsupported request item %T", item.RequestedItems)
+		}
+
+		resp.ResponseItems = append(resp.ResponseItems, &respItem)
+	}
+
+	return &resp, nil
+}
+
+func (ac *FakeAdmissionControl) latestVersion() uint64 {
+	return uint64(len(ac.versions) - 1)
+}
+
+func (ac *FakeAdmissionControl) infoHashes() [][]byte {
+	hashes := make([][]byte, len(ac.versions))
+	for i, v := range ac.versions {
+		hashes[i] = verifier.HashTransactionInfo(v.info)
+	}
+	return hashes
+}
+
+func (ac *FakeAdmissionControl) ledgerInfo() *types.LedgerInfoWithSignatures {
+	ledgerInfo := types.LedgerInfo{
+		Version:                    ac.latestVersion(),
+		TransactionAccumulatorHash: accumulatorRoot(ac.infoHashes(), hasher.TransactionAccumulatorNode),
+		ConsensusDataHash:          make([]byte, hasher.HashLength),
+		ConsensusBlockId:           make([]byte, hasher.HashLength),
+	}
+
+	result := types.LedgerInfoWithSignatures{LedgerInfo: &ledgerInfo}
+
+	hash := hasher.LedgerInfo(&ledgerInfo)
+	for _, key := range ac.Validators {
+		result.Signatures = append(result.Signatures, &types.ValidatorSignature{
+			ValidatorId: key.Address(),
+			Signature:   ed25519.Sign(key.PrivateKey, hash),
+		})
+	}
+
+	return &result
+}
+
+func (ac *FakeAdmissionControl) transactionProof(version uint64) *types.AccumulatorProof {
+	return accumulatorProof(ac.infoHashes(), version, hasher.TransactionAccumulatorNode)
+}
+
+func (ac *FakeAdmissionControl) accountState(address []byte) *types.AccountStateWithProof {
+	version := ac.latestVersion()
+	latest := ac.versions[version]
+
+	result := types.AccountStateWithProof{
+		Version: version,
+		Proof: &types.AccountStateProof{
+			LedgerInfoToTransactionInfoProof: ac.transactionProof(version),
+			TransactionInfo:                  latest.info,
+			TransactionInfoToAccountProof:    sparseMerkleProof(latest.state, hasher.AccountAddress(address)),
+		},
+	}
+
+	if blob, ok := latest.accounts[string(address)]; ok {
+		result.Blob = &types.AccountStateBlob{Blob: blob}
+	}
+
+	return &result
+}
+
+func (ac *FakeAdmissionControl) accountTransaction(req *types.GetAccountTransactionBySequenceNumberRequest) *types.GetAccountTransactionBySequenceNumberResponse {
+	for version, v := range ac.versions {
+		raw := types.RawTransaction{}
+		if err := proto.Unmarshal(v.transaction.GetRawTxnBytes(), &raw); err != nil {
+			continue
+		}
+		if !bytes.Equal(raw.SenderAccount, req.Account) || raw.SequenceNumber != req.SequenceNumber {
+			continue
+		}
+
+		txn := types.SignedTransactionWithProof{
+			Version:           uint64(version),
+			SignedTransaction: v.transaction,
+			Proof: &types.SignedTransactionProof{
+				LedgerInfoToTransactionInfoProof: ac.transactionProof(uint64(version)),
+				TransactionInfo:                  v.info,
+			},
+		}
+		if req.FetchEvents {
+			txn.Events = &types.EventsList{Events: v.events}
+		}
+
+		return &types.GetAccountTransactionBySequenceNumberResponse{SignedTransactionWithProof: &txn}
+	}
+
+	return &types.GetAccountTransactionBySequenceNumberResponse{
+		ProofOfCurrentSequenceNumber: ac.accountState(req.Account),
+	}
+}
+
+func (ac *FakeAdmissionControl) events(req *types.GetEventsByEventAccessPathRequest) *types.GetEventsByEventAccessPathResponse {
+	key := append(append([]byte{}, req.AccessPath.GetAddress()...), req.AccessPath.GetPath()...)
+
+	var all []*types.EventWithProof
+	for version, v := range ac.versions {
+		for index, event := range v.events {
+			if bytes.Equal(event.Key, key) {
+				all = append(all, &types.EventWithProof{
+					TransactionVersion: uint64(version),
+					EventIndex:         uint64(index),
+					Event:              event,
+				})
+			}
+		}
+	}
+
+	var selected []*types.EventWithProof
+	if req.Ascending {
+		for i := 0; i < len(all) && uint64(len(selected)) < req.Limit; i++ {
+			if all[i].Event.SequenceNumber >= req.StartEventSeqNum {
+				selected = append(selected, all[i])
+			}
+		}
+	} else {
+		for i := len(all) - 1; i >= 0 && uint64(len(selected)) < req.Limit; i-- {
+			if all[i].Event.SequenceNumber <= req.StartEventSeqNum {
+				selected = append(selected, all[i])
+			}
+		}
+	}
+
+	resp := types.GetEventsByEventAccessPathResponse{}
+	for _, e := range selected {
+		v := ac.versions[e.TransactionVersion]
+
+		eventHashes := make([][]byte, len(v.events))
+		for i, event := range v.events {
+			eventHashes[i] = hasher.ContractEvent(event)
+		}
+
+		e.Proof = &types.EventProof{
+			LedgerInfoToTransactionInfoProof: ac.transactionProof(e.TransactionVersion),
+			TransactionInfo:                  v.info,
+			TransactionInfoToEventProof:      accumulatorProof(eventHashes, e.EventIndex, hasher.EventAccumulatorNode),
+		}
+		resp.EventsWithProof = append(resp.EventsWithProof, e)
+	}
+
+	pastLatest := len(all) == 0 || req.StartEventSeqNum > all[len(all)-1].Event.SequenceNumber
+	if (req.Ascending && uint64(len(selected)) < req.Limit) || (!req.Ascending && pastLatest) {
+		resp.ProofOfLatestEvent = ac.accountState(req.AccessPath.GetAddress())
+	}
+
+	return &resp
+}
+
+func (ac *FakeAdmissionControl) transactions(req *types.GetTransactionsRequest) *types.TransactionListWithProof {
+	list := types.TransactionListWithProof{}
+	if req.StartVersion > ac.latestVersion() || req.Limit == 0 {
+		return &list
+	}
+
+	end := req.StartVersion + req.Limit
+	if end > uint64(len(ac.versions)) {
+		end = uint64(len(ac.versions))
+	}
+
+	if req.FetchEvents {
+		list.EventsForVersions = &types.EventsForVersions{}
+	}
+
+	for _, v := range ac.versions[req.StartVersion:end] {
+		list.Transactions = append(list.Transactions, v.transaction)
+		list.Infos = append(list.Infos, v.info)
+		if req.FetchEvents {
+			list.EventsForVersions.EventsForVersion = append(list.EventsForVersions.EventsForVersion, &types.EventsList{Events: v.events})
+		}
+	}
+
+	list.FirstTransactionVersion = &wrappers.UInt64Value{Value: req.StartVersion}
+	list.ProofOfFirstTransaction = ac.transactionProof(req.StartVersion)
+	if end-req.StartVersion > 1 {
+		list.ProofOfLastTransaction = ac.transactionProof(end - 1)
+	}
+
+	return &list
+}
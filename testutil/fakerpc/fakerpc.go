@@ -0,0 +1,58 @@
+// Package fakerpc connects a verifying LibraRPC to the fake nodes of
+// testutil. It is kept out of testutil, which the tests inside package
+// controllers import.
+package fakerpc
+
+import (
+	"testing"
+
+	"io.librablock.go/controllers"
+	"io.librablock.go/testutil"
+)
+
+// Dial serves ac and returns a LibraRPC connected to it over grpc, verifying
+// proofs and trusting the validators of ac, and a function that stops both.
+func Dial(t testing.TB, ac *testutil.FakeAdmissionControl) (*controllers.LibraRPC, func()) {
+	address, stop, err := testutil.ServeFakeAdmissionControl(ac)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return dial(t, address, controllers.DefaultRPCOptions(), ac, stop)
+}
+
+// DialWithStorage is Dial with a storage service answering historical
+// queries from the ledger of storageLedger, usually ac itself.
+func DialWithStorage(t testing.TB, ac *testutil.FakeAdmissionControl, storageLedger *testutil.FakeAdmissionControl) (*controllers.LibraRPC, func()) {
+	address, stopAC, err := testutil.ServeFakeAdmissionControl(ac)
+	if err != nil {
+		t.Fatal(err)
+	}
+	storageAddress, stopStorage, err := testutil.ServeFakeStorage(testutil.NewFakeStorage(storageLedger))
+	if err != nil {
+		stopAC()
+		t.Fatal(err)
+	}
+
+	options := controllers.DefaultRPCOptions()
+	options.StorageAddress = storageAddress
+	return dial(t, address, options, ac, func() {
+		stopStorage()
+		stopAC()
+	})
+}
+
+func dial(t testing.TB, address string, options controllers.RPCOptions, ac *testutil.FakeAdmissionControl, stop func()) (*controllers.LibraRPC, func()) {
+	libra, err := controllers.NewLibraRPC(&address, options)
+	if err != nil {
+		stop()
+		t.Fatal(err)
+	}
+
+	libra.VerifyProofs = true
+	libra.TrustedState = ac.TrustedState()
+	return libra, func() {
+		libra.Close()
+		stop()
+	}
+}
@@ -0,0 +1,38 @@
+package testutil
+
+import (
+	"bytes"
+	"testing"
+
+	"io.librablock.go/keys"
+)
+
+// Alice and Bob are the accounts of PaymentLedger.
+var (
+	Alice = bytes.Repeat([]byte{0xaa}, 32)
+	Bob   = bytes.Repeat([]byte{0xbb}, 32)
+)
+
+// PaymentLedger is a fake node whose ledger holds payments from Alice to
+// Bob, see CommitPayments.
+func PaymentLedger(t testing.TB, payments int) *FakeAdmissionControl {
+	ac := NewFakeAdmissionControl()
+	if err := ac.CommitPayments(Alice, Bob, payments); err != nil {
+		t.Fatal(err)
+	}
+	return ac
+}
+
+// FakeValidators generates n validator keys for FakeAdmissionControl to
+// sign its ledger infos with.
+func FakeValidators(t testing.TB, n int) []*keys.Key {
+	var validators []*keys.Key
+	for i := 0; i < n; i++ {
+		key, err := keys.GenerateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		validators = append(validators, key)
+	}
+	return validators
+}
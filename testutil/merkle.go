@@ -0,0 +1,147 @@
+package testutil
+
+import (
+	"bytes"
+	"sort"
+
+	"io.librablock.go/hasher"
+	"io.librablock.go/proto/types"
+)
+
+// The builders below produce the roots and proofs a Libra node would, for
+// ledgers small enough to rebuild from scratch on every request.
+
+type nodeHasher func(left []byte, right []byte) []byte
+
+func accumulatorDepth(n uint64) uint {
+	var depth uint
+	for uint64(1)<<depth < n {
+		depth++
+	}
+	return depth
+}
+
+// accumulatorNode hashes the subtree of the given height whose leftmost leaf
+// is first. Subtrees without any leaves are the placeholder hash.
+func accumulatorNode(leaves [][]byte, first uint64, height uint, hash nodeHasher) []byte {
+	if first >= uint64(len(leaves)) {
+		return hasher.AccumulatorPlaceholderHash
+	}
+	if height == 0 {
+		return leaves[first]
+	}
+
+	half := uint64(1) << (height - 1)
+	return hash(accumulatorNode(leaves, first, height-1, hash), accumulatorNode(leaves, first+half, height-1, hash))
+}
+
+func accumulatorRoot(leaves [][]byte, hash nodeHasher) []byte {
+	if len(leaves) == 0 {
+		return hasher.AccumulatorPlaceholderHash
+	}
+	return accumulatorNode(leaves, 0, accumulatorDepth(uint64(len(leaves))), hash)
+}
+
+func accumulatorProof(leaves [][]byte, index uint64, hash nodeHasher) *types.AccumulatorProof {
+	proof := types.AccumulatorProof{}
+	depth := accumulatorDepth(uint64(len(leaves)))
+
+	// siblings from the root down, the root's children being bit depth-1
+	for level := depth; level > 0; level-- {
+		subtree := index >> (level - 1) << (level - 1)
+		sibling := subtree ^ (uint64(1) << (level - 1))
+
+		node := accumulatorNode(leaves, sibling, level-1, hash)
+		if bytes.Equal(node, hasher.AccumulatorPlaceholderHash) {
+			continue
+		}
+
+		proof.Bitmap |= 1 << (level - 1)
+		proof.NonDefaultSiblings = append(proof.NonDefaultSiblings, node)
+	}
+
+	return &proof
+}
+
+type sparseMerkleLeaf struct {
+	key       []byte
+	valueHash []byte
+}
+
+// sparseMerkleLeaves hashes an account state into the sorted leaves of its
+// sparse merkle tree.
+func sparseMerkleLeaves(state map[string][]byte) []sparseMerkleLeaf {
+	leaves := make([]sparseMerkleLeaf, 0, len(state))
+	for address, blob := range state {
+		leaves = append(leaves, sparseMerkleLeaf{
+			key:       hasher.AccountAddress([]byte(address)),
+			valueHash: hasher.AccountStateBlob(blob),
+		})
+	}
+
+	sort.Slice(leaves, func(i, j int) bool {
+		return bytes.Compare(leaves[i].key, leaves[j].key) < 0
+	})
+	return leaves
+}
+
+func keyBit(key []byte, i int) bool {
+	return key[i/8]&(0x80>>uint(i%8)) != 0
+}
+
+// splitLeaves splits sorted leaves on bit i of their keys.
+func splitLeaves(leaves []sparseMerkleLeaf, i int) ([]sparseMerkleLeaf, []sparseMerkleLeaf) {
+	n := sort.Search(len(leaves), func(j int) bool {
+		return keyBit(leaves[j].key, i)
+	})
+	return leaves[:n], leaves[n:]
+}
+
+func sparseMerkleNode(leaves []sparseMerkleLeaf, depth int) []byte {
+	switch len(leaves) {
+	case 0:
+		return hasher.SparseMerklePlaceholderHash
+	case 1:
+		return hasher.SparseMerkleLeafNode(leaves[0].key, leaves[0].valueHash)
+	}
+
+	left, right := splitLeaves(leaves, depth)
+	return hasher.SparseMerkleInternalNode(sparseMerkleNode(left, depth+1), sparseMerkleNode(right, depth+1))
+}
+
+func sparseMerkleRoot(leaves []sparseMerkleLeaf) []byte {
+	return sparseMerkleNode(leaves, 0)
+}
+
+func sparseMerkleProof(leaves []sparseMerkleLeaf, key []byte) *types.SparseMerkleProof {
+	proof := types.SparseMerkleProof{}
+
+	depth := 0
+	for ; len(leaves) > 1; depth++ {
+		left, right := splitLeaves(leaves, depth)
+
+		sibling := right
+		leaves = left
+		if keyBit(key, depth) {
+			sibling = left
+			leaves = right
+		}
+
+		node := sparseMerkleNode(sibling, depth+1)
+		if depth/8 >= len(proof.Bitmap) {
+			proof.Bitmap = append(proof.Bitmap, 0)
+		}
+		if !bytes.Equal(node, hasher.SparseMerklePlaceholderHash) {
+			proof.Bitmap[depth/8] |= 0x80 >> uint(depth%8)
+			proof.NonDefaultSiblings = append(proof.NonDefaultSiblings, node)
+		}
+	}
+
+	// the sibling next to the leaf is never a placeholder, so the bitmap has
+	// no trailing zero bytes to trim
+	if len(leaves) == 1 {
+		proof.Leaf = append(append([]byte{}, leaves[0].key...), leaves[0].valueHash...)
+	}
+
+	return &proof
+}
@@ -0,0 +1,76 @@
+package testutil
+
+import (
+	"context"
+	"sync"
+
+	"golang.org/x/crypto/ed25519"
+	"io.librablock.go/keys"
+	"io.librablock.go/proto/secret_service"
+)
+
+// FakeSecretService is an in-memory SecretService that keeps generated keys
+// in process, for use in place of a real one.
+type FakeSecretService struct {
+	secret_service.UnimplementedSecretServiceServer
+
+	mu   sync.Mutex
+	keys map[string]*keys.Key
+}
+
+func NewFakeSecretService() *FakeSecretService {
+	return &FakeSecretService{keys: make(map[string]*keys.Key)}
+}
+
+// AddKey stores key and returns its key id.
+func (s *FakeSecretService) AddKey(key *keys.Key) []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keyID := key.AuthenticationKey()
+	s.keys[string(keyID)] = key
+
+	return keyID
+}
+
+func (s *FakeSecretService) key(keyID []byte) *keys.Key {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.keys[string(keyID)]
+}
+
+func (s *FakeSecretService) GenerateKey(ctx context.Context, req *secret_service.GenerateKeyRequest) (*secret_service.GenerateKeyResponse, error) {
+	if req.Spec != secret_service.KeyType_Ed25519 {
+		return &secret_service.GenerateKeyResponse{Code: secret_service.ErrorCode_InvalidParameters}, nil
+	}
+
+	key, err := keys.GenerateKey()
+	if err != nil {
+		return &secret_service.GenerateKeyResponse{Code: secret_service.ErrorCode_Unspecified}, nil
+	}
+
+	return &secret_service.GenerateKeyResponse{KeyId: s.AddKey(key), Code: secret_service.ErrorCode_Success}, nil
+}
+
+func (s *FakeSecretService) GetPublicKey(ctx context.Context, req *secret_service.PublicKeyRequest) (*secret_service.PublicKeyResponse, error) {
+	key := s.key(req.KeyId)
+	if key == nil {
+		return &secret_service.PublicKeyResponse{Code: secret_service.ErrorCode_KeyIdNotFound}, nil
+	}
+
+	return &secret_service.PublicKeyResponse{PublicKey: key.PublicKey, Code: secret_service.ErrorCode_Success}, nil
+}
+
+func (s *FakeSecretService) Sign(ctx context.Context, req *secret_service.SignRequest) (*secret_service.SignResponse, error) {
+	key := s.key(req.KeyId)
+	if key == nil {
+		return &secret_service.SignResponse{Code: secret_service.ErrorCode_KeyIdNotFound}, nil
+	}
+
+	if len(req.MessageHash) != 32 {
+		return &secret_service.SignResponse{Code: secret_service.ErrorCode_WrongLength}, nil
+	}
+
+	return &secret_service.SignResponse{Signature: ed25519.Sign(key.PrivateKey, req.MessageHash), Code: secret_service.ErrorCode_Success}, nil
+}
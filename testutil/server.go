@@ -0,0 +1,23 @@
+package testutil
+
+import (
+	"net"
+
+	"google.golang.org/grpc"
+)
+
+// Serve starts a grpc server on a free local port with the services added by
+// register, and returns its address and a function that stops it.
+func Serve(register func(s *grpc.Server)) (string, func(), error) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", nil, err
+	}
+
+	s := grpc.NewServer()
+	register(s)
+
+	go s.Serve(lis)
+
+	return lis.Addr().String(), s.Stop, nil
+}
@@ -0,0 +1,50 @@
+package testutil
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"io.librablock.go/hasher"
+	"io.librablock.go/proto/storage"
+	"io.librablock.go/proto/types"
+)
+
+// FakeStorage is a storage service answering historical account state
+// queries from the ledger of a FakeAdmissionControl.
+type FakeStorage struct {
+	storage.UnimplementedStorageServer
+
+	ac *FakeAdmissionControl
+}
+
+func NewFakeStorage(ac *FakeAdmissionControl) *FakeStorage {
+	return &FakeStorage{ac: ac}
+}
+
+// ServeFakeStorage serves s on a free local port, see Serve.
+func ServeFakeStorage(s *FakeStorage) (string, func(), error) {
+	return Serve(func(server *grpc.Server) {
+		storage.RegisterStorageServer(server, s)
+	})
+}
+
+func (s *FakeStorage) GetAccountStateWithProofByVersion(ctx context.Context, req *storage.GetAccountStateWithProofByVersionRequest) (*storage.GetAccountStateWithProofByVersionResponse, error) {
+	s.ac.mu.Lock()
+	defer s.ac.mu.Unlock()
+
+	if req.Version >= uint64(len(s.ac.versions)) {
+		return nil, status.Errorf(codes.NotFound, "version %d not found", req.Version)
+	}
+	v := s.ac.versions[req.Version]
+
+	resp := storage.GetAccountStateWithProofByVersionResponse{
+		SparseMerkleProof: sparseMerkleProof(v.state, hasher.AccountAddress(req.Address)),
+	}
+	if blob, ok := v.accounts[string(req.Address)]; ok {
+		resp.AccountStateBlob = &types.AccountStateBlob{Blob: blob}
+	}
+
+	return &resp, nil
+}
@@ -0,0 +1,99 @@
+package transaction
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"io.librablock.go/proto/types"
+)
+
+const (
+	AddressLength = 32
+
+	DefaultMaxGasAmount = 140000
+	DefaultGasUnitPrice = 0
+	DefaultExpiration   = 100 * time.Second
+)
+
+type Options struct {
+	MaxGasAmount uint64
+	GasUnitPrice uint64
+	// ExpirationTime is when the transaction stops being valid; the zero
+	// value means DefaultExpiration from now.
+	ExpirationTime time.Time
+}
+
+func DefaultOptions() Options {
+	return Options{
+		MaxGasAmount: DefaultMaxGasAmount,
+		GasUnitPrice: DefaultGasUnitPrice,
+	}
+}
+
+func U64Argument(v uint64) *types.TransactionArgument {
+	data := make([]byte, 8)
+	binary.LittleEndian.PutUint64(data, v)
+	return &types.TransactionArgument{Type: types.TransactionArgument_U64, Data: data}
+}
+
+func AddressArgument(address []byte) *types.TransactionArgument {
+	return &types.TransactionArgument{Type: types.TransactionArgument_ADDRESS, Data: address}
+}
+
+func ByteArrayArgument(data []byte) *types.TransactionArgument {
+	return &types.TransactionArgument{Type: types.TransactionArgument_BYTEARRAY, Data: data}
+}
+
+// NewRawTransaction builds a transaction running the named script with args.
+func NewRawTransaction(sender []byte, sequenceNumber uint64, script string, args []*types.TransactionArgument, opts Options) (*types.RawTransaction, error) {
+	if len(sender) != AddressLength {
+		return nil, fmt.Errorf("sender address has length %d", len(sender))
+	}
+
+	code, err := Script(script)
+	if err != nil {
+		return nil, err
+	}
+
+	expiration := opts.ExpirationTime
+	if expiration.IsZero() {
+		expiration = time.Now().Add(DefaultExpiration)
+	}
+
+	return &types.RawTransaction{
+		SenderAccount:  sender,
+		SequenceNumber: sequenceNumber,
+		Payload: &types.RawTransaction_Program{
+			Program: &types.Program{
+				Code:      code,
+				Arguments: args,
+			},
+		},
+		MaxGasAmount:   opts.MaxGasAmount,
+		GasUnitPrice:   opts.GasUnitPrice,
+		ExpirationTime: uint64(expiration.Unix()),
+	}, nil
+}
+
+func NewPeerToPeer(sender []byte, sequenceNumber uint64, receiver []byte, amount uint64, opts Options) (*types.RawTransaction, error) {
+	if len(receiver) != AddressLength {
+		return nil, fmt.Errorf("receiver address has length %d", len(receiver))
+	}
+
+	return NewRawTransaction(sender, sequenceNumber, PeerToPeerScript, []*types.TransactionArgument{
+		AddressArgument(receiver),
+		U64Argument(amount),
+	}, opts)
+}
+
+func NewMint(sender []byte, sequenceNumber uint64, receiver []byte, amount uint64, opts Options) (*types.RawTransaction, error) {
+	if len(receiver) != AddressLength {
+		return nil, fmt.Errorf("receiver address has length %d", len(receiver))
+	}
+
+	return NewRawTransaction(sender, sequenceNumber, MintScript, []*types.TransactionArgument{
+		AddressArgument(receiver),
+		U64Argument(amount),
+	}, opts)
+}
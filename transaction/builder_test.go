@@ -0,0 +1,135 @@
+package transaction
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"golang.org/x/crypto/ed25519"
+	"io.librablock.go/keys"
+	"io.librablock.go/proto/types"
+)
+
+// withScript registers code for name without checking its fingerprint, as
+// the released bytecode is not available to the tests.
+func withScript(name string, code []byte) func() {
+	scriptsMu.Lock()
+	defer scriptsMu.Unlock()
+
+	old, ok := scripts[name]
+	scripts[name] = code
+	return func() {
+		scriptsMu.Lock()
+		defer scriptsMu.Unlock()
+
+		if ok {
+			scripts[name] = old
+		} else {
+			delete(scripts, name)
+		}
+	}
+}
+
+func TestNewPeerToPeer(t *testing.T) {
+	code := []byte("peer to peer")
+	defer withScript(PeerToPeerScript, code)()
+
+	sender := bytes.Repeat([]byte{0xaa}, AddressLength)
+	receiver := bytes.Repeat([]byte{0xbb}, AddressLength)
+	expiration := time.Unix(1565000000, 0)
+
+	raw, err := NewPeerToPeer(sender, 7, receiver, 1000000, Options{MaxGasAmount: 10000, GasUnitPrice: 2, ExpirationTime: expiration})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(raw.SenderAccount, sender) || raw.SequenceNumber != 7 || raw.MaxGasAmount != 10000 || raw.GasUnitPrice != 2 || raw.ExpirationTime != 1565000000 {
+		t.Errorf("got %+v", raw)
+	}
+
+	program := raw.GetProgram()
+	if program == nil || !bytes.Equal(program.Code, code) || len(program.Arguments) != 2 {
+		t.Fatalf("got program %+v", program)
+	}
+	if arg := program.Arguments[0]; arg.Type != types.TransactionArgument_ADDRESS || !bytes.Equal(arg.Data, receiver) {
+		t.Errorf("got receiver argument %+v", arg)
+	}
+	if arg := program.Arguments[1]; arg.Type != types.TransactionArgument_U64 || binary.LittleEndian.Uint64(arg.Data) != 1000000 {
+		t.Errorf("got amount argument %+v", arg)
+	}
+}
+
+func TestNewRawTransactionDefaults(t *testing.T) {
+	defer withScript(MintScript, []byte("mint"))()
+
+	sender := bytes.Repeat([]byte{0xaa}, AddressLength)
+	before := time.Now()
+
+	raw, err := NewMint(sender, 0, sender, 1, DefaultOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if raw.MaxGasAmount != DefaultMaxGasAmount || raw.GasUnitPrice != DefaultGasUnitPrice {
+		t.Errorf("got %+v", raw)
+	}
+	if expiration := time.Unix(int64(raw.ExpirationTime), 0); expiration.Before(before.Add(DefaultExpiration).Add(-time.Second)) || expiration.After(time.Now().Add(DefaultExpiration)) {
+		t.Errorf("got expiration %v", expiration)
+	}
+}
+
+func TestNewRawTransactionErrors(t *testing.T) {
+	address := bytes.Repeat([]byte{0xaa}, AddressLength)
+
+	if _, err := NewPeerToPeer(address, 0, address, 1, DefaultOptions()); err == nil {
+		t.Error("built a transaction without registered bytecode")
+	}
+
+	defer withScript(PeerToPeerScript, []byte("peer to peer"))()
+
+	if _, err := NewPeerToPeer(address[:31], 0, address, 1, DefaultOptions()); err == nil {
+		t.Error("built a transaction from a short sender address")
+	}
+	if _, err := NewPeerToPeer(address, 0, address[:31], 1, DefaultOptions()); err == nil {
+		t.Error("built a transaction to a short receiver address")
+	}
+}
+
+func TestSign(t *testing.T) {
+	defer withScript(PeerToPeerScript, []byte("peer to peer"))()
+
+	key, err := keys.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := NewPeerToPeer(key.Address(), 3, bytes.Repeat([]byte{0xbb}, AddressLength), 5, DefaultOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signed, err := Sign(context.Background(), raw, keys.NewLocalSigner(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(signed.SenderPublicKey, key.PublicKey) {
+		t.Errorf("got public key %x", signed.SenderPublicKey)
+	}
+	if !ed25519.Verify(key.PublicKey, Hash(signed.RawTxnBytes), signed.SenderSignature) {
+		t.Error("signature does not verify against the transaction hash")
+	}
+
+	decoded := types.RawTransaction{}
+	if err := proto.Unmarshal(signed.RawTxnBytes, &decoded); err != nil || !proto.Equal(&decoded, raw) {
+		t.Errorf("raw transaction bytes decode to %+v, %v", decoded, err)
+	}
+
+	signed.RawTxnBytes[len(signed.RawTxnBytes)-1] ^= 1
+	if ed25519.Verify(key.PublicKey, Hash(signed.RawTxnBytes), signed.SenderSignature) {
+		t.Error("signature verifies against changed bytes")
+	}
+}
@@ -0,0 +1,59 @@
+//go:build ignore
+// +build ignore
+
+// gen_scripts bundles the compiled transaction scripts in scripts/*.mv into
+// bytecode.go. Run it through go generate after replacing a script.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+func main() {
+	files, err := filepath.Glob(filepath.Join("scripts", "*.mv"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	sort.Strings(files)
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by gen_scripts.go; DO NOT EDIT.\n\n")
+	buf.WriteString("package transaction\n\n")
+	buf.WriteString("// bundledScripts is the bytecode of scripts/*.mv by script name.\n")
+	buf.WriteString("var bundledScripts = map[string][]byte{\n")
+
+	for _, file := range files {
+		code, err := ioutil.ReadFile(file)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		name := strings.TrimSuffix(filepath.Base(file), ".mv")
+		fmt.Fprintf(&buf, "%q: {", name)
+		for i, b := range code {
+			if i%16 == 0 {
+				buf.WriteString("\n")
+			}
+			fmt.Fprintf(&buf, "0x%02x, ", b)
+		}
+		buf.WriteString("\n},\n")
+	}
+
+	buf.WriteString("}\n")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile("bytecode.go", src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
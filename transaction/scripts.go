@@ -0,0 +1,93 @@
+package transaction
+
+import (
+	"crypto/md5"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"io.librablock.go/controllers"
+)
+
+const (
+	PeerToPeerScript = "peer_to_peer_transfer"
+	MintScript       = "mint"
+)
+
+//go:generate go run gen_scripts.go
+
+// fingerprints pins the bytecode accepted for each script to the program
+// hashes the fetcher classifies transactions by. A script without a pinned
+// fingerprint cannot be registered.
+var fingerprints = map[string]string{
+	PeerToPeerScript: controllers.P2pProgramMd5,
+	MintScript:       controllers.MintProgramMd5,
+}
+
+var (
+	scripts   = make(map[string][]byte)
+	scriptsMu sync.RWMutex
+)
+
+// init registers the bytecode bundled from scripts/*.mv, which must match
+// the pinned fingerprints.
+func init() {
+	for name, code := range bundledScripts {
+		if err := RegisterScript(name, code); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// RegisterScript makes code available as the bytecode of the named script.
+// The code must match the fingerprint pinned for the script.
+func RegisterScript(name string, code []byte) error {
+	fingerprint, ok := fingerprints[name]
+	if !ok {
+		return fmt.Errorf("no fingerprint pinned for script %s", name)
+	}
+	if sum := fmt.Sprintf("%x", md5.Sum(code)); sum != fingerprint {
+		return fmt.Errorf("bytecode for %s has md5 %s, expected %s", name, sum, fingerprint)
+	}
+
+	scriptsMu.Lock()
+	defer scriptsMu.Unlock()
+	scripts[name] = code
+
+	return nil
+}
+
+// LoadScripts registers every <script name>.mv file found in dir over the
+// bundled bytecode, e.g. the compiled transaction scripts shipped with the
+// Libra release the node runs. Other files are ignored.
+func LoadScripts(dir string) error {
+	for _, name := range []string{PeerToPeerScript, MintScript} {
+		code, err := ioutil.ReadFile(filepath.Join(dir, name+".mv"))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := RegisterScript(name, code); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func Script(name string) ([]byte, error) {
+	scriptsMu.RLock()
+	defer scriptsMu.RUnlock()
+
+	code, ok := scripts[name]
+	if !ok {
+		return nil, fmt.Errorf("no bytecode bundled or registered for script %s", name)
+	}
+
+	return code, nil
+}
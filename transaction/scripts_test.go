@@ -0,0 +1,64 @@
+package transaction
+
+import (
+	"crypto/md5"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBundledScriptsMatchFingerprints(t *testing.T) {
+	if len(bundledScripts) == 0 {
+		t.Skip("no script bytecode is bundled")
+	}
+
+	for name, code := range bundledScripts {
+		fingerprint, ok := fingerprints[name]
+		if !ok {
+			t.Errorf("%s is bundled without a fingerprint", name)
+			continue
+		}
+		if sum := fmt.Sprintf("%x", md5.Sum(code)); sum != fingerprint {
+			t.Errorf("%s has md5 %s, expected %s", name, sum, fingerprint)
+		}
+
+		if _, err := Script(name); err != nil {
+			t.Errorf("%s is bundled but not registered: %v", name, err)
+		}
+	}
+}
+
+func TestRegisterScriptChecksFingerprint(t *testing.T) {
+	if err := RegisterScript(PeerToPeerScript, []byte("not the released bytecode")); err == nil || !strings.Contains(err.Error(), "md5") {
+		t.Errorf("got %v, want a fingerprint mismatch", err)
+	}
+
+	if err := RegisterScript("some_script", []byte{0x01}); err == nil {
+		t.Error("registered a script without a fingerprint")
+	}
+}
+
+func TestLoadScriptsIgnoresUnknownFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "scripts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "rotate_authentication_key.mv"), []byte{0x01}, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadScripts(dir); err != nil {
+		t.Errorf("got %v", err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, MintScript+".mv"), []byte("not the released bytecode"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadScripts(dir); err == nil {
+		t.Error("loaded bytecode that does not match its fingerprint")
+	}
+}
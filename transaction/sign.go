@@ -0,0 +1,39 @@
+package transaction
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/proto"
+	"io.librablock.go/hasher"
+	"io.librablock.go/keys"
+	"io.librablock.go/proto/types"
+)
+
+// Hash is the message a sender signs: the salted hash of the serialized raw
+// transaction bytes.
+func Hash(rawTxnBytes []byte) []byte {
+	return hasher.Sum(hasher.RawTransactionSalt, rawTxnBytes)
+}
+
+func Sign(ctx context.Context, raw *types.RawTransaction, signer keys.Signer) (*types.SignedTransaction, error) {
+	rawTxnBytes, err := proto.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, err := signer.PublicKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := signer.Sign(ctx, Hash(rawTxnBytes))
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.SignedTransaction{
+		RawTxnBytes:     rawTxnBytes,
+		SenderPublicKey: publicKey,
+		SenderSignature: signature,
+	}, nil
+}
@@ -1,92 +1,309 @@
 package utils
 
 import (
-	"fmt"
+	"errors"
+	"strings"
+	"time"
 
 	"github.com/jinzhu/gorm"
 	_ "github.com/jinzhu/gorm/dialects/mysql"
+	_ "github.com/jinzhu/gorm/dialects/postgres"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+	"io.librablock.go/migrations"
 	"io.librablock.go/models"
 )
 
+var ErrNotFound = errors.New("not found")
+
+var _ Store = DataBaseAdapter{}
+
 type DataBaseAdapter struct {
-	url string
+	url     string
+	dialect string
+	dsn     string
+	db      *gorm.DB
 }
 
-func NewDataBaseAdapter(url string) DataBaseAdapter {
+// NewDataBaseAdapter opens the connection pool shared by every query, on the
+// backend picked by ParseDatabaseURL. Call Close when done.
+func NewDataBaseAdapter(url string, options DBOptions) (DataBaseAdapter, error) {
 	d := DataBaseAdapter{}
 	d.url = url
-	return d
+
+	dialect, dsn, err := ParseDatabaseURL(url)
+	if err != nil {
+		return d, err
+	}
+	d.dialect = dialect
+	d.dsn = dsn
+
+	db, err := gorm.Open(dialect, dsn)
+	if err != nil {
+		return d, err
+	}
+
+	if dialect == SQLiteDialect {
+		// sqlite allows a single writer; more connections only trade
+		// waiting in the pool for "database is locked" errors
+		options.MaxOpenConns = 1
+	}
+
+	db.DB().SetMaxOpenConns(options.MaxOpenConns)
+	db.DB().SetMaxIdleConns(options.MaxIdleConns)
+	db.DB().SetConnMaxLifetime(options.ConnMaxLifetime)
+
+	d.db = db
+	return d, nil
 }
 
+// GetURL returns the DSN handed to the database driver.
 func (database DataBaseAdapter) GetURL() string {
-	return fmt.Sprintf("%s?charset=utf8mb4&parseTime=True&loc=Local", database.url)
+	return database.dsn
 }
 
-func (database DataBaseAdapter) GetDB() *gorm.DB {
-	db, err := gorm.Open("mysql", database.GetURL())
+func (database DataBaseAdapter) Dialect() string {
+	return database.dialect
+}
 
-	if err != nil {
-		panic("failed to connect database")
-	}
+func (database DataBaseAdapter) GetDB() *gorm.DB {
+	return database.db
+}
 
-	return db
+func (database DataBaseAdapter) Close() error {
+	return database.db.Close()
 }
 
-func (database DataBaseAdapter) Migration() {
-	db := database.GetDB()
-	defer db.Close()
+// Migration applies every pending schema migration, see package migrations.
+func (database DataBaseAdapter) Migration() error {
+	if database.dialect == SQLiteDialect {
+		// let the API read while the fetcher writes
+		if err := database.db.Exec("PRAGMA journal_mode=WAL").Error; err != nil {
+			return err
+		}
+	}
 
-	db.AutoMigrate(&models.BlockModel{})
+	_, err := migrations.Up(database.db, database.dialect)
+	return err
 }
 
-func (database DataBaseAdapter) GetLatestVersion() uint64 {
-	db := database.GetDB()
-	defer db.Close()
+func (database DataBaseAdapter) GetLatestVersion() (uint64, error) {
 	result := models.BlockModel{}
-	db.Order("version desc").First(&result)
+	err := database.db.Order("version desc").First(&result).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return 0, nil
+	}
 
-	return result.Version
+	return result.Version, err
 }
 
-func (database DataBaseAdapter) GetVersion(id uint64) models.BlockModel {
-	db := database.GetDB()
-	defer db.Close()
-
+func (database DataBaseAdapter) GetVersion(id uint64) (models.BlockModel, error) {
 	var result models.BlockModel
-	db.Where("version = ?", id).First(&result)
+	err := database.db.Where("version = ?", id).First(&result).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return result, ErrNotFound
+	}
 
-	return result
+	return result, err
 }
 
-func (database DataBaseAdapter) GetVersions(offset int, limit int) []models.BlockModel {
-	db := database.GetDB()
-	defer db.Close()
+func (database DataBaseAdapter) GetVersions(offset int, limit int) ([]models.BlockModel, error) {
 	if limit > 50 {
 		limit = 50
 	}
 
 	var blocks []models.BlockModel
-	db.Order("version desc").Offset(offset).Limit(limit).Find(&blocks)
+	err := database.db.Order("version desc").Offset(offset).Limit(limit).Find(&blocks).Error
 
-	return blocks
+	return blocks, err
 }
 
-func (database DataBaseAdapter) GetVersionsRefAddress(address string, offset int, limit int) []models.BlockModel {
-	db := database.GetDB()
-	defer db.Close()
+func (database DataBaseAdapter) GetVersionsRefAddress(address string, offset int, limit int) ([]models.BlockModel, error) {
 	if limit > 50 {
 		limit = 50
 	}
 
+	// addresses are stored in lowercase hex; only MySQL compares strings
+	// case-insensitively
+	address = strings.ToLower(address)
+
 	var blocks []models.BlockModel
-	db.Where("source = ?", address).Or("destination = ?", address).Order("version desc").Offset(offset).Limit(limit).Find(&blocks)
+	err := database.db.Where("source = ?", address).Or("destination = ?", address).Order("version desc").Offset(offset).Limit(limit).Find(&blocks).Error
+
+	return blocks, err
+}
+
+// SaveBlock stores a block together with its events, or nothing at all. A
+// block already stored with the same version is overwritten.
+func (database DataBaseAdapter) SaveBlock(model models.BlockModel) error {
+	tx := database.db.Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	if err := database.upsertBlock(tx, model); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
 
-	return blocks
+// SaveBlocks stores a batch like SaveBlock and moves the checkpoint to its
+// highest version, all in one transaction.
+func (database DataBaseAdapter) SaveBlocks(blocks []models.BlockModel) error {
+	if len(blocks) == 0 {
+		return nil
+	}
+
+	tx := database.db.Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	var checkpoint uint64
+	for _, model := range blocks {
+		if err := database.upsertBlock(tx, model); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if model.Version > checkpoint {
+			checkpoint = model.Version
+		}
+	}
+
+	if err := tx.Exec(advanceCheckpointQuery(database.dialect), fetcherStateID, time.Now(), checkpoint).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
 }
 
-func (database DataBaseAdapter) SaveBlock(model models.BlockModel) {
-	db := database.GetDB()
-	defer db.Close()
+// upsertBlock inserts the block, or updates the row of the same version in
+// place, and replaces its events. The conflict is detected by the unique
+// version index of migration 1; without it MySQL would insert a duplicate.
+func (database DataBaseAdapter) upsertBlock(tx *gorm.DB, model models.BlockModel) error {
+	if model.CreatedAt.IsZero() {
+		model.CreatedAt = time.Now()
+	}
+
+	var columns []string
+	var values []interface{}
+	for _, field := range tx.NewScope(&model).Fields() {
+		if field.IsPrimaryKey || field.IsIgnored || !field.IsNormal {
+			continue
+		}
+
+		columns = append(columns, field.DBName)
+		values = append(values, field.Field.Interface())
+	}
+
+	query := upsertQuery(database.dialect, "block_models", columns, "version", "created_at")
+	if err := tx.Exec(query, values...).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Where("version = ?", model.Version).Delete(&models.EventModel{}).Error; err != nil {
+		return err
+	}
+
+	for _, event := range model.Events {
+		if err := tx.Create(&event).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// upsertQuery inserts a row of columns into table, or updates the row with
+// the same unique key in place, leaving key and the kept columns alone.
+// MySQL spells it ON DUPLICATE KEY UPDATE, PostgreSQL and SQLite ON CONFLICT.
+func upsertQuery(dialect string, table string, columns []string, key string, kept ...string) string {
+	quote := func(name string) string {
+		if dialect == MySQLDialect {
+			return "`" + name + "`"
+		}
+		return `"` + name + `"`
+	}
+
+	skip := map[string]bool{key: true}
+	for _, column := range kept {
+		skip[column] = true
+	}
+
+	var quoted, placeholders, updates []string
+	for _, column := range columns {
+		quoted = append(quoted, quote(column))
+		placeholders = append(placeholders, "?")
+
+		if skip[column] {
+			continue
+		}
+		if dialect == MySQLDialect {
+			updates = append(updates, quote(column)+" = VALUES("+quote(column)+")")
+		} else {
+			updates = append(updates, quote(column)+" = excluded."+quote(column))
+		}
+	}
+
+	upsert := " ON CONFLICT (" + quote(key) + ") DO UPDATE SET "
+	if dialect == MySQLDialect {
+		upsert = " ON DUPLICATE KEY UPDATE "
+	}
+
+	return "INSERT INTO " + quote(table) + " (" + strings.Join(quoted, ", ") + ") VALUES (" + strings.Join(placeholders, ", ") + ")" +
+		upsert + strings.Join(updates, ", ")
+}
+
+func (database DataBaseAdapter) GetEventsByVersion(version uint64) ([]models.EventModel, error) {
+	var events []models.EventModel
+	err := database.db.Where("version = ?", version).Order("event_index asc").Find(&events).Error
+
+	return events, err
+}
+
+func (database DataBaseAdapter) GetEventsByKey(key string, offset int, limit int) ([]models.EventModel, error) {
+	if limit > 50 {
+		limit = 50
+	}
+
+	// keys are stored in lowercase hex
+	var events []models.EventModel
+	err := database.db.Where("event_key = ?", strings.ToLower(key)).Order("sequence_number desc").Offset(offset).Limit(limit).Find(&events).Error
+
+	return events, err
+}
+
+// fetcherStateID is the primary key of the only fetcher state row.
+const fetcherStateID = 1
+
+func (database DataBaseAdapter) GetCheckpoint() (uint64, error) {
+	state := models.FetcherStateModel{}
+	err := database.db.First(&state, fetcherStateID).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return database.GetLatestVersion()
+	}
+
+	return state.Version, err
+}
+
+// advanceCheckpointQuery creates the fetcher state row, or raises its version
+// unless it is already higher, in a single statement so that concurrent
+// fetchers cannot interleave between a read and a write.
+func advanceCheckpointQuery(dialect string) string {
+	insert := "INSERT INTO fetcher_state_models (id, updated_at, version) VALUES (?, ?, ?)"
+
+	switch dialect {
+	case MySQLDialect:
+		return insert + " ON DUPLICATE KEY UPDATE updated_at = VALUES(updated_at), version = GREATEST(version, VALUES(version))"
+	case PostgresDialect:
+		return insert + " ON CONFLICT (id) DO UPDATE SET updated_at = excluded.updated_at, version = GREATEST(fetcher_state_models.version, excluded.version)"
+	default:
+		return insert + " ON CONFLICT (id) DO UPDATE SET updated_at = excluded.updated_at, version = MAX(fetcher_state_models.version, excluded.version)"
+	}
+}
 
-	db.Create(&model)
+func (database DataBaseAdapter) SaveCheckpoint(version uint64) error {
+	return database.db.Save(&models.FetcherStateModel{ID: fetcherStateID, Version: version}).Error
 }
@@ -0,0 +1,126 @@
+package utils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"io.librablock.go/models"
+)
+
+// testDatabases opens a migrated temporary SQLite database, and the
+// databases listed in LIBRA_TEST_DATABASE_URLS, which must be empty and
+// disposable. The returned function drops their tables and closes them.
+func testDatabases(t *testing.T) ([]DataBaseAdapter, func()) {
+	dir, err := ioutil.TempDir("", "libra-store")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	urls := append([]string{"sqlite://" + filepath.Join(dir, "test.db")}, strings.Fields(os.Getenv("LIBRA_TEST_DATABASE_URLS"))...)
+
+	var databases []DataBaseAdapter
+	closeAll := func() {
+		for _, database := range databases {
+			database.GetDB().DropTableIfExists("event_models", "block_models", "fetcher_state_models", "schema_version")
+			database.Close()
+		}
+		os.RemoveAll(dir)
+	}
+
+	for _, url := range urls {
+		database, err := NewDataBaseAdapter(url, DefaultDBOptions())
+		if err != nil {
+			closeAll()
+			t.Fatalf("%s: %v", url, err)
+		}
+		databases = append(databases, database)
+
+		if err := database.Migration(); err != nil {
+			closeAll()
+			t.Fatalf("%s: %v", url, err)
+		}
+	}
+
+	return databases, closeAll
+}
+
+func TestUpsertQuery(t *testing.T) {
+	tests := []struct {
+		dialect string
+		want    string
+	}{
+		{MySQLDialect, "INSERT INTO `block_models` (`created_at`, `version`, `amount`) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE `amount` = VALUES(`amount`)"},
+		{PostgresDialect, `INSERT INTO "block_models" ("created_at", "version", "amount") VALUES (?, ?, ?) ON CONFLICT ("version") DO UPDATE SET "amount" = excluded."amount"`},
+		{SQLiteDialect, `INSERT INTO "block_models" ("created_at", "version", "amount") VALUES (?, ?, ?) ON CONFLICT ("version") DO UPDATE SET "amount" = excluded."amount"`},
+	}
+
+	for _, tt := range tests {
+		got := upsertQuery(tt.dialect, "block_models", []string{"created_at", "version", "amount"}, "version", "created_at")
+		if got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.dialect, got, tt.want)
+		}
+	}
+}
+
+func TestDataBaseAdapterIgnoresCase(t *testing.T) {
+	databases, closeAll := testDatabases(t)
+	defer closeAll()
+
+	address := strings.Repeat("ab", 32)
+	key := strings.Repeat("cd", 40)
+
+	for _, database := range databases {
+		err := database.SaveBlocks([]models.BlockModel{{
+			Version: 1,
+			Source:  address,
+			Events:  []models.EventModel{{Version: 1, Key: key}},
+		}})
+		if err != nil {
+			t.Fatalf("%s: %v", database.Dialect(), err)
+		}
+
+		blocks, err := database.GetVersionsRefAddress(strings.ToUpper(address), 0, 10)
+		if err != nil || len(blocks) != 1 {
+			t.Errorf("%s: got %v, %v by uppercase address", database.Dialect(), blocks, err)
+		}
+
+		events, err := database.GetEventsByKey(strings.ToUpper(key), 0, 10)
+		if err != nil || len(events) != 1 {
+			t.Errorf("%s: got %v, %v by uppercase key", database.Dialect(), events, err)
+		}
+	}
+}
+
+func TestDataBaseAdapterUpsert(t *testing.T) {
+	databases, closeAll := testDatabases(t)
+	defer closeAll()
+
+	for _, database := range databases {
+		first := models.BlockModel{Version: 7, Amount: 1, Events: []models.EventModel{{Version: 7, EventIndex: 0}, {Version: 7, EventIndex: 1}}}
+		if err := database.SaveBlock(first); err != nil {
+			t.Fatalf("%s: %v", database.Dialect(), err)
+		}
+		stored, err := database.GetVersion(7)
+		if err != nil {
+			t.Fatalf("%s: %v", database.Dialect(), err)
+		}
+
+		second := models.BlockModel{Version: 7, Amount: 2, Events: []models.EventModel{{Version: 7, EventIndex: 0, Amount: 2}}}
+		if err := database.SaveBlock(second); err != nil {
+			t.Fatalf("%s: %v", database.Dialect(), err)
+		}
+
+		block, err := database.GetVersion(7)
+		if err != nil || block.Amount != 2 || block.ID != stored.ID || !block.CreatedAt.Equal(stored.CreatedAt) {
+			t.Errorf("%s: got %+v, %v after the second save of %+v", database.Dialect(), block, err, stored)
+		}
+
+		events, err := database.GetEventsByVersion(7)
+		if err != nil || len(events) != 1 || events[0].Amount != 2 {
+			t.Errorf("%s: got events %+v, %v", database.Dialect(), events, err)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	DefaultMaxOpenConns    = 20
+	DefaultMaxIdleConns    = 5
+	DefaultConnMaxLifetime = 5 * time.Minute
+)
+
+type DBOptions struct {
+	// MaxOpenConns bounds the connections of the pool; zero means unlimited.
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+}
+
+func DefaultDBOptions() DBOptions {
+	return DBOptions{
+		MaxOpenConns:    DefaultMaxOpenConns,
+		MaxIdleConns:    DefaultMaxIdleConns,
+		ConnMaxLifetime: DefaultConnMaxLifetime,
+	}
+}
+
+// DBOptionsFromEnv reads LIBRA_DB_MAX_OPEN_CONNS, LIBRA_DB_MAX_IDLE_CONNS and
+// LIBRA_DB_CONN_MAX_LIFETIME (a duration such as "5m") on top of the defaults.
+func DBOptionsFromEnv() DBOptions {
+	options := DefaultDBOptions()
+
+	if v, err := strconv.Atoi(os.Getenv("LIBRA_DB_MAX_OPEN_CONNS")); err == nil {
+		options.MaxOpenConns = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("LIBRA_DB_MAX_IDLE_CONNS")); err == nil {
+		options.MaxIdleConns = v
+	}
+	if d, err := time.ParseDuration(os.Getenv("LIBRA_DB_CONN_MAX_LIFETIME")); err == nil {
+		options.ConnMaxLifetime = d
+	}
+
+	return options
+}
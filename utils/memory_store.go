@@ -0,0 +1,234 @@
+package utils
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"io.librablock.go/models"
+)
+
+// MemoryStore is a Store that keeps everything in process, for tests and for
+// running without a database.
+type MemoryStore struct {
+	mu         sync.RWMutex
+	blocks     map[uint64]models.BlockModel
+	versions   []uint64
+	events     []models.EventModel
+	checkpoint *uint64
+	nextID     uint
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{blocks: make(map[uint64]models.BlockModel)}
+}
+
+func (store *MemoryStore) Migration() error {
+	return nil
+}
+
+func (store *MemoryStore) Close() error {
+	return nil
+}
+
+func (store *MemoryStore) GetLatestVersion() (uint64, error) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	return store.latestVersion(), nil
+}
+
+func (store *MemoryStore) latestVersion() uint64 {
+	if len(store.versions) == 0 {
+		return 0
+	}
+	return store.versions[len(store.versions)-1]
+}
+
+func (store *MemoryStore) GetVersion(version uint64) (models.BlockModel, error) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	block, ok := store.blocks[version]
+	if !ok {
+		return models.BlockModel{}, ErrNotFound
+	}
+	return block, nil
+}
+
+// page walks the stored blocks from the newest one and keeps those accepted
+// by match, like "order by version desc offset limit".
+func (store *MemoryStore) page(offset int, limit int, match func(block models.BlockModel) bool) []models.BlockModel {
+	if limit > 50 {
+		limit = 50
+	}
+
+	var blocks []models.BlockModel
+	for i := len(store.versions) - 1; i >= 0 && len(blocks) < limit; i-- {
+		block := store.blocks[store.versions[i]]
+		if !match(block) {
+			continue
+		}
+		if offset > 0 {
+			offset--
+			continue
+		}
+		blocks = append(blocks, block)
+	}
+
+	return blocks
+}
+
+func (store *MemoryStore) GetVersions(offset int, limit int) ([]models.BlockModel, error) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	return store.page(offset, limit, func(models.BlockModel) bool { return true }), nil
+}
+
+func (store *MemoryStore) GetVersionsRefAddress(address string, offset int, limit int) ([]models.BlockModel, error) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	address = strings.ToLower(address)
+	return store.page(offset, limit, func(block models.BlockModel) bool {
+		return block.Source == address || block.Destination == address
+	}), nil
+}
+
+func (store *MemoryStore) SaveBlock(model models.BlockModel) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.saveBlock(model)
+	return nil
+}
+
+func (store *MemoryStore) SaveBlocks(blocks []models.BlockModel) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if len(blocks) == 0 {
+		return nil
+	}
+
+	var checkpoint uint64
+	for _, model := range blocks {
+		store.saveBlock(model)
+		if model.Version > checkpoint {
+			checkpoint = model.Version
+		}
+	}
+
+	if store.checkpoint == nil || checkpoint > *store.checkpoint {
+		store.checkpoint = &checkpoint
+	}
+	return nil
+}
+
+// saveBlock upserts like the database does: a block already stored with the
+// same version keeps its id and creation time, its events are replaced.
+func (store *MemoryStore) saveBlock(model models.BlockModel) {
+	now := time.Now()
+
+	if stored, ok := store.blocks[model.Version]; ok {
+		model.ID = stored.ID
+		model.CreatedAt = stored.CreatedAt
+
+		events := store.events[:0]
+		for _, event := range store.events {
+			if event.Version != model.Version {
+				events = append(events, event)
+			}
+		}
+		store.events = events
+	} else {
+		store.nextID++
+		model.ID = store.nextID
+		model.CreatedAt = now
+
+		i := sort.Search(len(store.versions), func(i int) bool { return store.versions[i] >= model.Version })
+		store.versions = append(store.versions, 0)
+		copy(store.versions[i+1:], store.versions[i:])
+		store.versions[i] = model.Version
+	}
+
+	for _, event := range model.Events {
+		store.nextID++
+		event.ID = store.nextID
+		event.CreatedAt = now
+		store.events = append(store.events, event)
+	}
+	model.Events = nil
+
+	store.blocks[model.Version] = model
+}
+
+func (store *MemoryStore) GetEventsByVersion(version uint64) ([]models.EventModel, error) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	var events []models.EventModel
+	for _, event := range store.events {
+		if event.Version == version {
+			events = append(events, event)
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool { return events[i].EventIndex < events[j].EventIndex })
+	return events, nil
+}
+
+func (store *MemoryStore) GetEventsByKey(key string, offset int, limit int) ([]models.EventModel, error) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	if limit > 50 {
+		limit = 50
+	}
+
+	key = strings.ToLower(key)
+
+	var matched []models.EventModel
+	for _, event := range store.events {
+		if event.Key == key {
+			matched = append(matched, event)
+		}
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool { return matched[i].SequenceNumber > matched[j].SequenceNumber })
+
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(matched) {
+		return nil, nil
+	}
+	matched = matched[offset:]
+	if limit >= 0 && len(matched) > limit {
+		matched = matched[:limit]
+	}
+
+	return matched, nil
+}
+
+func (store *MemoryStore) GetCheckpoint() (uint64, error) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	if store.checkpoint == nil {
+		return store.latestVersion(), nil
+	}
+	return *store.checkpoint, nil
+}
+
+func (store *MemoryStore) SaveCheckpoint(version uint64) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.checkpoint = &version
+	return nil
+}
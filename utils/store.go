@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"strings"
+
+	"io.librablock.go/models"
+)
+
+const MemoryStoreURL = "memory://"
+
+// Store is the storage used by the API server and the block fetcher.
+// Lookups of a single record return ErrNotFound when it does not exist.
+type Store interface {
+	Migration() error
+	Close() error
+
+	GetLatestVersion() (uint64, error)
+	GetVersion(version uint64) (models.BlockModel, error)
+	GetVersions(offset int, limit int) ([]models.BlockModel, error)
+
+	// SaveBlock stores a block and its events, overwriting those of a block
+	// already stored with the same version.
+	SaveBlock(model models.BlockModel) error
+	// SaveBlocks saves a batch like SaveBlock and moves the checkpoint to its
+	// highest version atomically: either all of it is stored or nothing. The
+	// checkpoint only ever advances, so a stale window saved late by another
+	// fetcher cannot move it back.
+	SaveBlocks(blocks []models.BlockModel) error
+
+	// GetVersionsRefAddress returns the blocks sent or received by address.
+	GetVersionsRefAddress(address string, offset int, limit int) ([]models.BlockModel, error)
+
+	GetEventsByVersion(version uint64) ([]models.EventModel, error)
+	GetEventsByKey(key string, offset int, limit int) ([]models.EventModel, error)
+
+	// GetCheckpoint returns the last version the fetcher has stored, which
+	// is the latest stored version until a checkpoint has been saved.
+	GetCheckpoint() (uint64, error)
+	// SaveCheckpoint sets the checkpoint, also to an earlier version.
+	SaveCheckpoint(version uint64) error
+}
+
+// OpenStore opens the database at url, or an empty MemoryStore for
+// "memory://".
+func OpenStore(url string, options DBOptions) (Store, error) {
+	if strings.HasPrefix(url, MemoryStoreURL) {
+		return NewMemoryStore(), nil
+	}
+
+	return NewDataBaseAdapter(url, options)
+}
@@ -0,0 +1,333 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"io.librablock.go/models"
+)
+
+var (
+	testAlice = strings.Repeat("aa", 32)
+	testBob   = strings.Repeat("bb", 32)
+	testCarol = strings.Repeat("cc", 32)
+)
+
+// testStores returns a MemoryStore and the databases of testDatabases, so
+// that every implementation passes the same tests.
+func testStores(t *testing.T) (map[string]Store, func()) {
+	databases, closeAll := testDatabases(t)
+
+	stores := map[string]Store{"memory": NewMemoryStore()}
+	for _, database := range databases {
+		stores[database.Dialect()] = database
+	}
+
+	return stores, closeAll
+}
+
+func testEventKey(address string) string {
+	return address + "01"
+}
+
+// testBlocks pays from alice to bob at even versions and from bob to carol at
+// odd ones, each payment emitting a sent and a received event.
+func testBlocks(from uint64, to uint64) []models.BlockModel {
+	var blocks []models.BlockModel
+	for version := from; version <= to; version++ {
+		source, destination := testAlice, testBob
+		if version%2 == 1 {
+			source, destination = testBob, testCarol
+		}
+
+		blocks = append(blocks, models.BlockModel{
+			Version:     version,
+			Source:      source,
+			Destination: destination,
+			Amount:      version * 10,
+			Events: []models.EventModel{
+				{Version: version, EventIndex: 0, Key: testEventKey(source), SequenceNumber: version / 2, Amount: version * 10},
+				{Version: version, EventIndex: 1, Key: testEventKey(destination), SequenceNumber: version / 2, Amount: version * 10},
+			},
+		})
+	}
+	return blocks
+}
+
+func versionsOf(blocks []models.BlockModel) []uint64 {
+	var versions []uint64
+	for _, block := range blocks {
+		versions = append(versions, block.Version)
+	}
+	return versions
+}
+
+func equalVersions(a []uint64, b []uint64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestStoreEmpty(t *testing.T) {
+	stores, closeAll := testStores(t)
+	defer closeAll()
+
+	for name, store := range stores {
+		if latest, err := store.GetLatestVersion(); err != nil || latest != 0 {
+			t.Errorf("%s: latest version %d, %v", name, latest, err)
+		}
+		if checkpoint, err := store.GetCheckpoint(); err != nil || checkpoint != 0 {
+			t.Errorf("%s: checkpoint %d, %v", name, checkpoint, err)
+		}
+		if _, err := store.GetVersion(1); err != ErrNotFound {
+			t.Errorf("%s: got %v for a missing version, want ErrNotFound", name, err)
+		}
+		if blocks, err := store.GetVersions(0, 10); err != nil || len(blocks) != 0 {
+			t.Errorf("%s: got %v, %v", name, blocks, err)
+		}
+		if err := store.SaveBlocks(nil); err != nil {
+			t.Errorf("%s: saving no blocks: %v", name, err)
+		}
+	}
+}
+
+func TestStoreBlocks(t *testing.T) {
+	stores, closeAll := testStores(t)
+	defer closeAll()
+
+	for name, store := range stores {
+		if err := store.SaveBlocks(testBlocks(1, 60)); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		if latest, err := store.GetLatestVersion(); err != nil || latest != 60 {
+			t.Errorf("%s: latest version %d, %v", name, latest, err)
+		}
+
+		block, err := store.GetVersion(7)
+		if err != nil || block.Source != testBob || block.Amount != 70 || block.CreatedAt.IsZero() {
+			t.Errorf("%s: got %+v, %v", name, block, err)
+		}
+
+		pages := []struct {
+			offset int
+			limit  int
+			want   []uint64
+		}{
+			{0, 3, []uint64{60, 59, 58}},
+			{3, 2, []uint64{57, 56}},
+			{58, 5, []uint64{2, 1}},
+			{60, 5, nil},
+		}
+		for _, page := range pages {
+			blocks, err := store.GetVersions(page.offset, page.limit)
+			if err != nil || !equalVersions(versionsOf(blocks), page.want) {
+				t.Errorf("%s: page %d+%d: got %v, %v", name, page.offset, page.limit, versionsOf(blocks), err)
+			}
+		}
+
+		// limits are capped at 50
+		if blocks, err := store.GetVersions(0, 100); err != nil || len(blocks) != 50 {
+			t.Errorf("%s: got %d blocks, %v", name, len(blocks), err)
+		}
+
+		blocks, err := store.GetVersionsRefAddress(testCarol, 1, 3)
+		if err != nil || !equalVersions(versionsOf(blocks), []uint64{57, 55, 53}) {
+			t.Errorf("%s: carol: got %v, %v", name, versionsOf(blocks), err)
+		}
+		blocks, err = store.GetVersionsRefAddress(strings.ToUpper(testBob), 0, 3)
+		if err != nil || !equalVersions(versionsOf(blocks), []uint64{60, 59, 58}) {
+			t.Errorf("%s: bob: got %v, %v", name, versionsOf(blocks), err)
+		}
+	}
+}
+
+func TestStoreEvents(t *testing.T) {
+	stores, closeAll := testStores(t)
+	defer closeAll()
+
+	for name, store := range stores {
+		if err := store.SaveBlocks(testBlocks(1, 10)); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		events, err := store.GetEventsByVersion(4)
+		if err != nil || len(events) != 2 || events[0].EventIndex != 0 || events[1].Key != testEventKey(testBob) {
+			t.Errorf("%s: got %+v, %v", name, events, err)
+		}
+
+		// carol received at versions 1, 3, 5, 7 and 9
+		events, err = store.GetEventsByKey(strings.ToUpper(testEventKey(testCarol)), 1, 2)
+		if err != nil || len(events) != 2 || events[0].Version != 7 || events[1].Version != 5 {
+			t.Errorf("%s: got %+v, %v", name, events, err)
+		}
+
+		if events, err := store.GetEventsByVersion(11); err != nil || len(events) != 0 {
+			t.Errorf("%s: got %+v, %v", name, events, err)
+		}
+	}
+}
+
+func TestStoreCheckpoint(t *testing.T) {
+	stores, closeAll := testStores(t)
+	defer closeAll()
+
+	for name, store := range stores {
+		// single saves leave the checkpoint at the latest version until one
+		// is saved
+		if err := store.SaveBlock(testBlocks(3, 3)[0]); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if checkpoint, err := store.GetCheckpoint(); err != nil || checkpoint != 3 {
+			t.Errorf("%s: checkpoint %d, %v", name, checkpoint, err)
+		}
+
+		if err := store.SaveCheckpoint(10); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if checkpoint, err := store.GetCheckpoint(); err != nil || checkpoint != 10 {
+			t.Errorf("%s: checkpoint %d, %v", name, checkpoint, err)
+		}
+
+		if err := store.SaveBlocks(testBlocks(11, 14)); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if checkpoint, err := store.GetCheckpoint(); err != nil || checkpoint != 14 {
+			t.Errorf("%s: checkpoint %d, %v", name, checkpoint, err)
+		}
+	}
+}
+
+func TestStoreCheckpointOnlyAdvances(t *testing.T) {
+	stores, closeAll := testStores(t)
+	defer closeAll()
+
+	for name, store := range stores {
+		if err := store.SaveBlocks(testBlocks(1, 20)); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		// a stale fetcher saves an older window after the newer one
+		if err := store.SaveBlocks(testBlocks(5, 10)); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if checkpoint, err := store.GetCheckpoint(); err != nil || checkpoint != 20 {
+			t.Errorf("%s: checkpoint %d, %v", name, checkpoint, err)
+		}
+		checkStoredOnce(t, name, store, 1, 20)
+
+		if err := store.SaveBlocks(testBlocks(21, 25)); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if checkpoint, err := store.GetCheckpoint(); err != nil || checkpoint != 25 {
+			t.Errorf("%s: checkpoint %d, %v", name, checkpoint, err)
+		}
+	}
+}
+
+// storedRows counts the rows of a version, including duplicates that the
+// lookups would hide.
+func storedRows(store Store, version uint64) (blocks int, events int, err error) {
+	database, ok := store.(DataBaseAdapter)
+	if !ok {
+		stored, err := store.GetEventsByVersion(version)
+		if _, getErr := store.GetVersion(version); getErr == nil {
+			blocks = 1
+		}
+		return blocks, len(stored), err
+	}
+
+	if err := database.GetDB().Model(&models.BlockModel{}).Where("version = ?", version).Count(&blocks).Error; err != nil {
+		return 0, 0, err
+	}
+	err = database.GetDB().Model(&models.EventModel{}).Where("version = ?", version).Count(&events).Error
+	return blocks, events, err
+}
+
+func checkStoredOnce(t *testing.T, name string, store Store, from uint64, to uint64) {
+	t.Helper()
+
+	for version := from; version <= to; version++ {
+		blocks, events, err := storedRows(store, version)
+		if err != nil || blocks != 1 || events != 2 {
+			t.Errorf("%s: version %d stored %d blocks and %d events, %v", name, version, blocks, events, err)
+		}
+	}
+}
+
+func TestStoreSavesWindowTwice(t *testing.T) {
+	stores, closeAll := testStores(t)
+	defer closeAll()
+
+	for name, store := range stores {
+		// the upserts rely on the unique version index of migration 1
+		if database, ok := store.(DataBaseAdapter); ok && !database.GetDB().Dialect().HasIndex("block_models", "block_version") {
+			t.Fatalf("%s: block_models.version is not unique", name)
+		}
+
+		if err := store.SaveBlocks(testBlocks(1, 10)); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		// a second fetcher, or a retry, stores the same window again
+		window := testBlocks(1, 10)
+		window[3].Amount = 1000
+		if err := store.SaveBlocks(window); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		checkStoredOnce(t, name, store, 1, 10)
+
+		if block, err := store.GetVersion(4); err != nil || block.Amount != 1000 {
+			t.Errorf("%s: got %+v, %v", name, block, err)
+		}
+		if blocks, err := store.GetVersionsRefAddress(testAlice, 0, 50); err != nil || len(blocks) != 5 {
+			t.Errorf("%s: got %d blocks of alice, %v", name, len(blocks), err)
+		}
+		if checkpoint, err := store.GetCheckpoint(); err != nil || checkpoint != 10 {
+			t.Errorf("%s: checkpoint %d, %v", name, checkpoint, err)
+		}
+	}
+}
+
+func TestStoreResumesAfterCrash(t *testing.T) {
+	stores, closeAll := testStores(t)
+	defer closeAll()
+
+	for name, store := range stores {
+		if err := store.SaveBlocks(testBlocks(1, 10)); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		// the fetcher stored part of the next window block by block and
+		// died before saving the checkpoint
+		for _, block := range testBlocks(11, 15) {
+			if err := store.SaveBlock(block); err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+		}
+		if checkpoint, err := store.GetCheckpoint(); err != nil || checkpoint != 10 {
+			t.Fatalf("%s: checkpoint %d, %v", name, checkpoint, err)
+		}
+
+		// on restart it fetches the window again from the checkpoint
+		if err := store.SaveBlocks(testBlocks(11, 20)); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		checkStoredOnce(t, name, store, 1, 20)
+
+		if checkpoint, err := store.GetCheckpoint(); err != nil || checkpoint != 20 {
+			t.Errorf("%s: checkpoint %d, %v", name, checkpoint, err)
+		}
+		if blocks, err := store.GetVersions(0, 50); err != nil || len(blocks) != 20 {
+			t.Errorf("%s: got %d blocks, %v", name, len(blocks), err)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package verifier
+
+import (
+	"bytes"
+	"math/bits"
+
+	"io.librablock.go/hasher"
+	"io.librablock.go/proto/types"
+)
+
+const maxAccumulatorProofDepth = 63
+
+type nodeHasher func(left []byte, right []byte) []byte
+
+// accumulatorSiblings expands the bitmap-compressed proof into its full list
+// of siblings, ordered from the root down to the leaf.
+func accumulatorSiblings(proof *types.AccumulatorProof) ([][]byte, error) {
+	if proof == nil {
+		return nil, errorf("missing accumulator proof")
+	}
+
+	bitmap := proof.Bitmap
+	if bits.OnesCount64(bitmap) != len(proof.NonDefaultSiblings) {
+		return nil, errorf("accumulator proof bitmap does not match %d non default siblings", len(proof.NonDefaultSiblings))
+	}
+
+	depth := 64 - bits.LeadingZeros64(bitmap)
+	if depth > maxAccumulatorProofDepth {
+		return nil, errorf("accumulator proof has more than %d siblings", maxAccumulatorProofDepth)
+	}
+
+	siblings := make([][]byte, 0, depth)
+	next := 0
+	for i := depth - 1; i >= 0; i-- {
+		if bitmap&(1<<uint(i)) == 0 {
+			siblings = append(siblings, hasher.AccumulatorPlaceholderHash)
+			continue
+		}
+
+		sibling := proof.NonDefaultSiblings[next]
+		if len(sibling) != hasher.HashLength {
+			return nil, errorf("accumulator proof sibling has length %d", len(sibling))
+		}
+		siblings = append(siblings, sibling)
+		next++
+	}
+
+	return siblings, nil
+}
+
+func verifyAccumulatorElement(root []byte, element []byte, index uint64, proof *types.AccumulatorProof, hash nodeHasher) error {
+	siblings, err := accumulatorSiblings(proof)
+	if err != nil {
+		return err
+	}
+
+	if index>>uint(len(siblings)) != 0 {
+		return errorf("element index %d is outside an accumulator of depth %d", index, len(siblings))
+	}
+
+	current := element
+	for i := len(siblings) - 1; i >= 0; i-- {
+		if index%2 == 0 {
+			current = hash(current, siblings[i])
+		} else {
+			current = hash(siblings[i], current)
+		}
+		index /= 2
+	}
+
+	if !bytes.Equal(current, root) {
+		return errorf("accumulator root hash mismatch")
+	}
+
+	return nil
+}
+
+// verifyAccumulatorRange rebuilds the root hash from a contiguous run of leaf
+// hashes starting at first, borrowing the left boundary siblings from the
+// proof of the first leaf and the right boundary siblings from the proof of
+// the last one.
+func verifyAccumulatorRange(root []byte, leaves [][]byte, first uint64, firstProof *types.AccumulatorProof, lastProof *types.AccumulatorProof, hash nodeHasher) error {
+	if len(leaves) == 0 {
+		return errorf("empty accumulator range")
+	}
+	last := first + uint64(len(leaves)) - 1
+
+	left, err := accumulatorSiblings(firstProof)
+	if err != nil {
+		return err
+	}
+	right, err := accumulatorSiblings(lastProof)
+	if err != nil {
+		return err
+	}
+
+	if len(left) != len(right) {
+		return errorf("first and last accumulator proofs have different depths")
+	}
+	depth := len(left)
+
+	if last < first || last>>uint(depth) != 0 {
+		return errorf("range %d - %d is outside an accumulator of depth %d", first, last, depth)
+	}
+
+	nodes := leaves
+	for level := 0; level < depth; level++ {
+		lo := first >> uint(level)
+		hi := last >> uint(level)
+
+		row := make([][]byte, 0, len(nodes)+2)
+		if lo%2 == 1 {
+			row = append(row, left[depth-1-level])
+		}
+		row = append(row, nodes...)
+		if hi%2 == 0 {
+			row = append(row, right[depth-1-level])
+		}
+
+		nodes = make([][]byte, 0, len(row)/2)
+		for i := 0; i+1 < len(row); i += 2 {
+			nodes = append(nodes, hash(row[i], row[i+1]))
+		}
+	}
+
+	if len(nodes) != 1 || !bytes.Equal(nodes[0], root) {
+		return errorf("accumulator root hash mismatch")
+	}
+
+	return nil
+}
+
+func HashTransactionInfo(info *types.TransactionInfo) []byte {
+	return hasher.TransactionInfo(info.SignedTransactionHash, info.StateRootHash, info.EventRootHash, info.GasUsed)
+}
+
+// VerifyTransactionInfo checks that info is the leaf at version of the
+// transaction accumulator summarized by ledgerInfo.
+func VerifyTransactionInfo(ledgerInfo *types.LedgerInfo, version uint64, info *types.TransactionInfo, proof *types.AccumulatorProof) error {
+	if ledgerInfo == nil || info == nil {
+		return errorf("missing ledger info or transaction info")
+	}
+
+	if version > ledgerInfo.Version {
+		return errorf("version %d is newer than ledger version %d", version, ledgerInfo.Version)
+	}
+
+	return verifyAccumulatorElement(ledgerInfo.TransactionAccumulatorHash, HashTransactionInfo(info), version, proof, hasher.TransactionAccumulatorNode)
+}
+
+// VerifyTransactionList checks that infos are the consecutive leaves of the
+// transaction accumulator summarized by ledgerInfo, starting at firstVersion.
+func VerifyTransactionList(ledgerInfo *types.LedgerInfo, firstVersion uint64, infos []*types.TransactionInfo, firstProof *types.AccumulatorProof, lastProof *types.AccumulatorProof) error {
+	if ledgerInfo == nil {
+		return errorf("missing ledger info")
+	}
+
+	if len(infos) == 0 {
+		return nil
+	}
+
+	lastVersion := firstVersion + uint64(len(infos)) - 1
+	if lastVersion > ledgerInfo.Version {
+		return errorf("version %d is newer than ledger version %d", lastVersion, ledgerInfo.Version)
+	}
+
+	if lastProof == nil && len(infos) == 1 {
+		lastProof = firstProof
+	}
+
+	leaves := make([][]byte, 0, len(infos))
+	for _, info := range infos {
+		if info == nil {
+			return errorf("missing transaction info")
+		}
+		leaves = append(leaves, HashTransactionInfo(info))
+	}
+
+	return verifyAccumulatorRange(ledgerInfo.TransactionAccumulatorHash, leaves, firstVersion, firstProof, lastProof, hasher.TransactionAccumulatorNode)
+}
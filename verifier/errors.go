@@ -0,0 +1,28 @@
+package verifier
+
+import "fmt"
+
+// Error is returned whenever data from the node fails verification, so that
+// callers can tell a bad proof apart from a transport failure.
+type Error struct {
+	msg string
+}
+
+func (e *Error) Error() string {
+	return "verification failed: " + e.msg
+}
+
+func errorf(format string, args ...interface{}) error {
+	return &Error{msg: fmt.Sprintf(format, args...)}
+}
+
+// Errorf reports a response that is internally inconsistent, e.g. proven data
+// that does not answer the request it was sent for.
+func Errorf(format string, args ...interface{}) error {
+	return errorf(format, args...)
+}
+
+func IsVerificationError(err error) bool {
+	_, ok := err.(*Error)
+	return ok
+}
@@ -0,0 +1,34 @@
+package verifier
+
+import (
+	"io.librablock.go/hasher"
+	"io.librablock.go/proto/types"
+)
+
+// VerifyEvent checks that event was emitted at index eventIndex by the
+// transaction at version, as summarized by ledgerInfo.
+func VerifyEvent(ledgerInfo *types.LedgerInfo, event *types.Event, version uint64, eventIndex uint64, proof *types.EventProof) error {
+	if event == nil || proof == nil {
+		return errorf("missing event or event proof")
+	}
+
+	info := proof.TransactionInfo
+	if info == nil {
+		return errorf("missing transaction info in event proof")
+	}
+
+	err := verifyAccumulatorElement(info.EventRootHash, hasher.ContractEvent(event), eventIndex, proof.TransactionInfoToEventProof, hasher.EventAccumulatorNode)
+	if err != nil {
+		return err
+	}
+
+	return VerifyTransactionInfo(ledgerInfo, version, info, proof.LedgerInfoToTransactionInfoProof)
+}
+
+func VerifyEventWithProof(ledgerInfo *types.LedgerInfo, event *types.EventWithProof) error {
+	if event == nil {
+		return errorf("missing event")
+	}
+
+	return VerifyEvent(ledgerInfo, event.Event, event.TransactionVersion, event.EventIndex, event.Proof)
+}
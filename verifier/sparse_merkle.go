@@ -0,0 +1,152 @@
+package verifier
+
+import (
+	"bytes"
+	"math/bits"
+
+	"io.librablock.go/hasher"
+	"io.librablock.go/proto/types"
+)
+
+const maxSparseMerkleProofDepth = hasher.HashLength * 8
+
+// sparseMerkleSiblings expands the bitmap-compressed proof into its full list
+// of siblings, ordered from the root down to the leaf.
+func sparseMerkleSiblings(proof *types.SparseMerkleProof) ([][]byte, error) {
+	bitmap := proof.Bitmap
+	if len(bitmap) == 0 {
+		if len(proof.NonDefaultSiblings) != 0 {
+			return nil, errorf("sparse merkle proof has siblings but an empty bitmap")
+		}
+		return nil, nil
+	}
+
+	last := bitmap[len(bitmap)-1]
+	if last == 0 {
+		return nil, errorf("last byte of sparse merkle proof bitmap is zero")
+	}
+
+	ones := 0
+	for _, b := range bitmap {
+		ones += bits.OnesCount8(b)
+	}
+	if ones != len(proof.NonDefaultSiblings) {
+		return nil, errorf("sparse merkle proof bitmap does not match %d non default siblings", len(proof.NonDefaultSiblings))
+	}
+
+	depth := len(bitmap)*8 - bits.TrailingZeros8(last)
+	if depth > maxSparseMerkleProofDepth {
+		return nil, errorf("sparse merkle proof has more than %d siblings", maxSparseMerkleProofDepth)
+	}
+
+	siblings := make([][]byte, 0, depth)
+	next := 0
+	for i := 0; i < depth; i++ {
+		if bitmap[i/8]&(0x80>>uint(i%8)) == 0 {
+			siblings = append(siblings, hasher.SparseMerklePlaceholderHash)
+			continue
+		}
+
+		sibling := proof.NonDefaultSiblings[next]
+		if len(sibling) != hasher.HashLength {
+			return nil, errorf("sparse merkle proof sibling has length %d", len(sibling))
+		}
+		siblings = append(siblings, sibling)
+		next++
+	}
+
+	return siblings, nil
+}
+
+func keyBit(key []byte, i int) bool {
+	return key[i/8]&(0x80>>uint(i%8)) != 0
+}
+
+func commonPrefixBits(a []byte, b []byte) int {
+	n := 0
+	for n < len(a)*8 && keyBit(a, n) == keyBit(b, n) {
+		n++
+	}
+	return n
+}
+
+// VerifySparseMerkleElement checks that key maps to the account state blob in
+// the sparse merkle tree with the given root. A nil blob asks for a proof that
+// key is absent.
+func VerifySparseMerkleElement(root []byte, key []byte, blob []byte, proof *types.SparseMerkleProof) error {
+	if proof == nil {
+		return errorf("missing sparse merkle proof")
+	}
+
+	if len(key) != hasher.HashLength {
+		return errorf("sparse merkle key has length %d", len(key))
+	}
+
+	siblings, err := sparseMerkleSiblings(proof)
+	if err != nil {
+		return err
+	}
+
+	var leafKey, leafValueHash []byte
+	switch len(proof.Leaf) {
+	case 0:
+	case hasher.HashLength * 2:
+		leafKey = proof.Leaf[:hasher.HashLength]
+		leafValueHash = proof.Leaf[hasher.HashLength:]
+	default:
+		return errorf("sparse merkle proof leaf has length %d", len(proof.Leaf))
+	}
+
+	if blob != nil {
+		if leafKey == nil {
+			return errorf("expected an inclusion proof, got a non-inclusion proof")
+		}
+		if !bytes.Equal(key, leafKey) {
+			return errorf("sparse merkle proof is for a different key")
+		}
+		if !bytes.Equal(hasher.AccountStateBlob(blob), leafValueHash) {
+			return errorf("sparse merkle leaf value hash mismatch")
+		}
+	} else if leafKey != nil {
+		if bytes.Equal(key, leafKey) {
+			return errorf("expected a non-inclusion proof, got an inclusion proof")
+		}
+		if commonPrefixBits(key, leafKey) < len(siblings) {
+			return errorf("sparse merkle proof leaf does not share the key prefix")
+		}
+	}
+
+	current := hasher.SparseMerklePlaceholderHash
+	if leafKey != nil {
+		current = hasher.SparseMerkleLeafNode(leafKey, leafValueHash)
+	}
+
+	for i := len(siblings) - 1; i >= 0; i-- {
+		if keyBit(key, i) {
+			current = hasher.SparseMerkleInternalNode(siblings[i], current)
+		} else {
+			current = hasher.SparseMerkleInternalNode(current, siblings[i])
+		}
+	}
+
+	if !bytes.Equal(current, root) {
+		return errorf("sparse merkle root hash mismatch")
+	}
+
+	return nil
+}
+
+// VerifyAccountState checks the state of address at version against
+// ledgerInfo. A nil blob asks for a proof that the account does not exist.
+func VerifyAccountState(ledgerInfo *types.LedgerInfo, version uint64, address []byte, blob []byte, proof *types.AccountStateProof) error {
+	if proof == nil || proof.TransactionInfo == nil {
+		return errorf("missing account state proof")
+	}
+
+	err := VerifySparseMerkleElement(proof.TransactionInfo.StateRootHash, hasher.AccountAddress(address), blob, proof.TransactionInfoToAccountProof)
+	if err != nil {
+		return err
+	}
+
+	return VerifyTransactionInfo(ledgerInfo, version, proof.TransactionInfo, proof.LedgerInfoToTransactionInfoProof)
+}
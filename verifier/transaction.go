@@ -0,0 +1,83 @@
+package verifier
+
+import (
+	"bytes"
+
+	"github.com/golang/protobuf/proto"
+	"io.librablock.go/hasher"
+	"io.librablock.go/proto/types"
+)
+
+// accumulatorRoot builds the root hash of an accumulator holding leaves,
+// where subtrees without any leaves are the placeholder hash.
+func accumulatorRoot(leaves [][]byte, hash nodeHasher) []byte {
+	if len(leaves) == 0 {
+		return hasher.AccumulatorPlaceholderHash
+	}
+
+	nodes := leaves
+	for len(nodes) > 1 {
+		next := make([][]byte, 0, (len(nodes)+1)/2)
+		for i := 0; i < len(nodes); i += 2 {
+			right := hasher.AccumulatorPlaceholderHash
+			if i+1 < len(nodes) {
+				right = nodes[i+1]
+			}
+			next = append(next, hash(nodes[i], right))
+		}
+		nodes = next
+	}
+
+	return nodes[0]
+}
+
+// EventRootHash is the root of the event accumulator of a transaction that
+// emitted events.
+func EventRootHash(events []*types.Event) ([]byte, error) {
+	leaves := make([][]byte, 0, len(events))
+	for _, event := range events {
+		if event == nil {
+			return nil, errorf("missing event")
+		}
+		leaves = append(leaves, hasher.ContractEvent(event))
+	}
+
+	return accumulatorRoot(leaves, hasher.EventAccumulatorNode), nil
+}
+
+// VerifySignedTransaction checks that txn is the transaction info commits to.
+func VerifySignedTransaction(info *types.TransactionInfo, txn *types.SignedTransaction) error {
+	if info == nil || txn == nil {
+		return errorf("missing transaction info or transaction")
+	}
+
+	data, err := proto.Marshal(txn)
+	if err != nil {
+		return err
+	}
+
+	if !bytes.Equal(hasher.SignedTransaction(data), info.SignedTransactionHash) {
+		return errorf("signed transaction hash mismatch")
+	}
+
+	return nil
+}
+
+// VerifyEventList checks that events are all the events, in order, that
+// info commits to.
+func VerifyEventList(info *types.TransactionInfo, events []*types.Event) error {
+	if info == nil {
+		return errorf("missing transaction info")
+	}
+
+	root, err := EventRootHash(events)
+	if err != nil {
+		return err
+	}
+
+	if !bytes.Equal(root, info.EventRootHash) {
+		return errorf("event root hash mismatch")
+	}
+
+	return nil
+}
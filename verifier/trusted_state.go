@@ -0,0 +1,262 @@
+package verifier
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ed25519"
+	"io.librablock.go/hasher"
+	"io.librablock.go/lcs"
+	"io.librablock.go/proto/types"
+)
+
+type Validator struct {
+	AccountAddress     string `json:"account_address"`
+	ConsensusPublicKey string `json:"consensus_public_key"`
+}
+
+// TrustedState is the validator set and the last ledger version this client
+// has verified. It is persisted as JSON and must be bootstrapped with the
+// validator set of a known epoch, e.g. the genesis validators.
+type TrustedState struct {
+	Epoch      uint64      `json:"epoch"`
+	Version    uint64      `json:"version"`
+	Validators []Validator `json:"validators"`
+
+	// ValidatorChangeEventKey is the hex encoded key of the events that
+	// announce the next validator set. Epoch changes are refused without it.
+	ValidatorChangeEventKey string `json:"validator_change_event_key,omitempty"`
+
+	path  string
+	saved time.Time
+	mu    sync.Mutex
+}
+
+// saveInterval throttles rewriting the trusted state file while the version
+// advances within an epoch; epoch changes are saved right away.
+const saveInterval = time.Minute
+
+func LoadTrustedState(path string) (*TrustedState, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	state := TrustedState{}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+
+	if len(state.Validators) == 0 {
+		return nil, errorf("trusted state %s has no validators", path)
+	}
+
+	state.path = path
+	state.saved = time.Now()
+	return &state, nil
+}
+
+// Save writes the trusted state back to the file it was loaded from, e.g.
+// on shutdown so that the latest verified version is not lost.
+func (state *TrustedState) Save() error {
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	return state.save()
+}
+
+func (state *TrustedState) save() error {
+	if state.path == "" {
+		return nil
+	}
+	state.saved = time.Now()
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := state.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, state.path)
+}
+
+func (state *TrustedState) KnownVersion() uint64 {
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	return state.Version
+}
+
+// Verify checks the ledger info of resp against the trusted validator set,
+// first walking through any validator changes it carries. On success the
+// trusted state advances to the new epoch and version. It is persisted when
+// the epoch changes, and at most every saveInterval otherwise.
+func (state *TrustedState) Verify(resp *types.UpdateToLatestLedgerResponse) error {
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	if resp == nil || resp.LedgerInfoWithSigs == nil || resp.LedgerInfoWithSigs.LedgerInfo == nil {
+		return errorf("missing ledger info")
+	}
+
+	epoch := state.Epoch
+	validators := state.Validators
+
+	for _, change := range resp.ValidatorChangeEvents {
+		if change.LedgerInfoWithSigs == nil || change.LedgerInfoWithSigs.LedgerInfo == nil {
+			return errorf("missing ledger info in validator change")
+		}
+
+		ledgerInfo := change.LedgerInfoWithSigs.LedgerInfo
+		if ledgerInfo.EpochNum < epoch {
+			continue
+		}
+		if ledgerInfo.EpochNum != epoch {
+			return errorf("validator change for epoch %d while trusting epoch %d", ledgerInfo.EpochNum, epoch)
+		}
+
+		if err := verifySignatures(change.LedgerInfoWithSigs, validators); err != nil {
+			return err
+		}
+
+		if err := VerifyEventWithProof(ledgerInfo, change.EventWithProof); err != nil {
+			return err
+		}
+
+		// any event proven under the ledger info would do otherwise
+		if err := state.checkValidatorChangeKey(change.EventWithProof.Event.Key); err != nil {
+			return err
+		}
+
+		next, err := decodeValidatorSet(change.EventWithProof.Event.EventData)
+		if err != nil {
+			return err
+		}
+
+		epoch++
+		validators = next
+	}
+
+	ledgerInfo := resp.LedgerInfoWithSigs.LedgerInfo
+	if ledgerInfo.EpochNum != epoch {
+		return errorf("ledger info is for epoch %d while trusting epoch %d", ledgerInfo.EpochNum, epoch)
+	}
+
+	if err := verifySignatures(resp.LedgerInfoWithSigs, validators); err != nil {
+		return err
+	}
+
+	if epoch == state.Epoch && ledgerInfo.Version <= state.Version {
+		return nil
+	}
+
+	epochChanged := epoch != state.Epoch
+
+	state.Epoch = epoch
+	state.Validators = validators
+	if ledgerInfo.Version > state.Version {
+		state.Version = ledgerInfo.Version
+	}
+
+	if !epochChanged && time.Since(state.saved) < saveInterval {
+		return nil
+	}
+	return state.save()
+}
+
+func (state *TrustedState) checkValidatorChangeKey(key []byte) error {
+	if state.ValidatorChangeEventKey == "" {
+		return errorf("validator change without a trusted validator change event key")
+	}
+
+	expected, err := hex.DecodeString(state.ValidatorChangeEventKey)
+	if err != nil {
+		return errorf("bad validator change event key: %v", err)
+	}
+
+	if !bytes.Equal(key, expected) {
+		return errorf("validator change from event key %x", key)
+	}
+	return nil
+}
+
+// verifySignatures requires a valid signature from at least 2f+1 of the
+// validators, where f = (n-1)/3. Signatures from authors outside the set do
+// not count and are ignored.
+func verifySignatures(ledgerInfoWithSigs *types.LedgerInfoWithSignatures, validators []Validator) error {
+	keys := make(map[string]ed25519.PublicKey)
+	for _, v := range validators {
+		key, err := hex.DecodeString(v.ConsensusPublicKey)
+		if err != nil || len(key) != ed25519.PublicKeySize {
+			return errorf("bad consensus public key for validator %s", v.AccountAddress)
+		}
+		keys[v.AccountAddress] = key
+	}
+
+	hash := hasher.LedgerInfo(ledgerInfoWithSigs.LedgerInfo)
+	signed := make(map[string]bool)
+
+	for _, sig := range ledgerInfoWithSigs.Signatures {
+		author := hex.EncodeToString(sig.ValidatorId)
+		key, ok := keys[author]
+		if !ok {
+			continue
+		}
+
+		if !ed25519.Verify(key, hash, sig.Signature) {
+			return errorf("invalid signature from validator %s", author)
+		}
+		signed[author] = true
+	}
+
+	n := len(validators)
+	quorum := 2*((n-1)/3) + 1
+	if len(signed) < quorum {
+		return errorf("ledger info signed by %d validators, need %d of %d", len(signed), quorum, n)
+	}
+
+	return nil
+}
+
+func decodeValidatorSet(data []byte) ([]Validator, error) {
+	d := lcs.NewDecoder(data)
+
+	count, err := d.DecodeLength()
+	if err != nil {
+		return nil, errorf("bad validator set: %v", err)
+	}
+
+	var validators []Validator
+	for i := 0; i < count; i++ {
+		var fields [4][]byte
+		for j := range fields {
+			if fields[j], err = d.DecodeBytes(); err != nil {
+				return nil, errorf("bad validator set: %v", err)
+			}
+		}
+
+		validators = append(validators, Validator{
+			AccountAddress:     hex.EncodeToString(fields[0]),
+			ConsensusPublicKey: hex.EncodeToString(fields[1]),
+		})
+	}
+
+	if err := d.Finish(); err != nil {
+		return nil, errorf("bad validator set: %v", err)
+	}
+
+	if len(validators) == 0 {
+		return nil, errorf("empty validator set")
+	}
+
+	return validators, nil
+}
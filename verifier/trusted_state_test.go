@@ -0,0 +1,250 @@
+package verifier
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/crypto/ed25519"
+	"io.librablock.go/hasher"
+	"io.librablock.go/lcs"
+	"io.librablock.go/proto/types"
+)
+
+type testValidator struct {
+	address []byte
+	key     ed25519.PrivateKey
+}
+
+func newTestValidators(t *testing.T, n int) []testValidator {
+	validators := make([]testValidator, n)
+	for i := range validators {
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		address := make([]byte, 32)
+		address[0] = byte(i + 1)
+		validators[i] = testValidator{address: address, key: key}
+	}
+	return validators
+}
+
+func trustedValidators(validators []testValidator) []Validator {
+	var result []Validator
+	for _, v := range validators {
+		result = append(result, Validator{
+			AccountAddress:     hex.EncodeToString(v.address),
+			ConsensusPublicKey: hex.EncodeToString(v.key.Public().(ed25519.PublicKey)),
+		})
+	}
+	return result
+}
+
+func signLedgerInfo(ledgerInfo *types.LedgerInfo, signers []testValidator) *types.LedgerInfoWithSignatures {
+	result := types.LedgerInfoWithSignatures{LedgerInfo: ledgerInfo}
+	hash := hasher.LedgerInfo(ledgerInfo)
+	for _, v := range signers {
+		result.Signatures = append(result.Signatures, &types.ValidatorSignature{
+			ValidatorId: v.address,
+			Signature:   ed25519.Sign(v.key, hash),
+		})
+	}
+
+	return &result
+}
+
+func signedResponse(version uint64, signers []testValidator) *types.UpdateToLatestLedgerResponse {
+	ledgerInfo := types.LedgerInfo{
+		Version:                    version,
+		TransactionAccumulatorHash: make([]byte, hasher.HashLength),
+		ConsensusDataHash:          make([]byte, hasher.HashLength),
+		ConsensusBlockId:           make([]byte, hasher.HashLength),
+	}
+
+	return &types.UpdateToLatestLedgerResponse{LedgerInfoWithSigs: signLedgerInfo(&ledgerInfo, signers)}
+}
+
+// validatorChange announces next in the only event of the only transaction
+// of epoch 0, signed by signers.
+func validatorChange(key []byte, next []testValidator, signers []testValidator) *types.ValidatorChangeEventWithProof {
+	data := lcs.NewEncoder().EncodeU32(uint32(len(next)))
+	for _, v := range next {
+		data.EncodeBytes(v.address).
+			EncodeBytes(v.key.Public().(ed25519.PublicKey)).
+			EncodeBytes(v.address).
+			EncodeBytes(v.key.Public().(ed25519.PublicKey))
+	}
+
+	event := types.Event{Key: key, EventData: data.Result()}
+	info := types.TransactionInfo{
+		SignedTransactionHash: make([]byte, hasher.HashLength),
+		StateRootHash:         make([]byte, hasher.HashLength),
+		EventRootHash:         hasher.ContractEvent(&event),
+	}
+
+	// single leaf accumulators are their own root, with empty proofs
+	ledgerInfo := types.LedgerInfo{
+		TransactionAccumulatorHash: hasher.TransactionInfo(info.SignedTransactionHash, info.StateRootHash, info.EventRootHash, info.GasUsed),
+		ConsensusDataHash:          make([]byte, hasher.HashLength),
+		ConsensusBlockId:           make([]byte, hasher.HashLength),
+	}
+
+	return &types.ValidatorChangeEventWithProof{
+		LedgerInfoWithSigs: signLedgerInfo(&ledgerInfo, signers),
+		EventWithProof: &types.EventWithProof{
+			Event: &event,
+			Proof: &types.EventProof{
+				LedgerInfoToTransactionInfoProof: &types.AccumulatorProof{},
+				TransactionInfo:                  &info,
+				TransactionInfoToEventProof:      &types.AccumulatorProof{},
+			},
+		},
+	}
+}
+
+func TestVerifyQuorum(t *testing.T) {
+	validators := newTestValidators(t, 4)
+	outsiders := newTestValidators(t, 2)
+	for i := range outsiders {
+		outsiders[i].address[1] = 0xff
+	}
+
+	tests := []struct {
+		name    string
+		signers []testValidator
+		ok      bool
+	}{
+		{"all validators", validators, true},
+		{"quorum", validators[:3], true},
+		{"quorum and unknown signers", append(append([]testValidator{}, validators[:3]...), outsiders...), true},
+		{"below quorum", validators[:2], false},
+		{"below quorum padded with unknown signers", append(append([]testValidator{}, validators[:2]...), outsiders...), false},
+		{"duplicated signer", []testValidator{validators[0], validators[0], validators[1]}, false},
+	}
+
+	for _, tt := range tests {
+		state := TrustedState{Validators: trustedValidators(validators)}
+		err := state.Verify(signedResponse(10, tt.signers))
+		if tt.ok && err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+		}
+		if !tt.ok && !IsVerificationError(err) {
+			t.Errorf("%s: got %v, want a verification error", tt.name, err)
+		}
+	}
+}
+
+func TestVerifyRejectsInvalidSignature(t *testing.T) {
+	validators := newTestValidators(t, 4)
+	state := TrustedState{Validators: trustedValidators(validators)}
+
+	resp := signedResponse(10, validators)
+	resp.LedgerInfoWithSigs.Signatures[0].Signature[0] ^= 1
+
+	if err := state.Verify(resp); !IsVerificationError(err) {
+		t.Errorf("got %v, want a verification error", err)
+	}
+}
+
+func TestVerifyThrottlesSaving(t *testing.T) {
+	dir, err := ioutil.TempDir("", "trusted_state")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	validators := newTestValidators(t, 4)
+	path := filepath.Join(dir, "trusted_state.json")
+
+	data, err := json.Marshal(TrustedState{Validators: trustedValidators(validators)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	state, err := LoadTrustedState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	savedVersion := func() uint64 {
+		saved, err := LoadTrustedState(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return saved.Version
+	}
+
+	for version := uint64(1); version <= 5; version++ {
+		if err := state.Verify(signedResponse(version, validators)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if state.KnownVersion() != 5 || savedVersion() != 0 {
+		t.Errorf("got known version %d and saved version %d", state.KnownVersion(), savedVersion())
+	}
+
+	if err := state.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if savedVersion() != 5 {
+		t.Errorf("got saved version %d after Save", savedVersion())
+	}
+
+	state.saved = state.saved.Add(-saveInterval)
+	if err := state.Verify(signedResponse(6, validators)); err != nil {
+		t.Fatal(err)
+	}
+	if savedVersion() != 6 {
+		t.Errorf("got saved version %d once the interval passed", savedVersion())
+	}
+}
+
+func TestVerifyValidatorChange(t *testing.T) {
+	validators := newTestValidators(t, 4)
+	next := newTestValidators(t, 4)
+
+	key := make([]byte, 40)
+	key[0] = 0x1d
+	otherKey := make([]byte, 40)
+	otherKey[0] = 0x1e
+
+	tests := []struct {
+		name     string
+		trusted  string
+		eventKey []byte
+		ok       bool
+	}{
+		{"validator change event", hex.EncodeToString(key), key, true},
+		{"unrelated event", hex.EncodeToString(key), otherKey, false},
+		{"no trusted key", "", key, false},
+	}
+
+	for _, tt := range tests {
+		state := TrustedState{Validators: trustedValidators(validators), ValidatorChangeEventKey: tt.trusted}
+
+		resp := signedResponse(10, next)
+		resp.LedgerInfoWithSigs.LedgerInfo.EpochNum = 1
+		resp.LedgerInfoWithSigs = signLedgerInfo(resp.LedgerInfoWithSigs.LedgerInfo, next)
+		resp.ValidatorChangeEvents = []*types.ValidatorChangeEventWithProof{validatorChange(tt.eventKey, next, validators)}
+
+		err := state.Verify(resp)
+		if tt.ok {
+			if err != nil || state.Epoch != 1 || state.Validators[0].ConsensusPublicKey != trustedValidators(next)[0].ConsensusPublicKey {
+				t.Errorf("%s: got %v, epoch %d", tt.name, err, state.Epoch)
+			}
+			continue
+		}
+		if !IsVerificationError(err) || state.Epoch != 0 {
+			t.Errorf("%s: got %v, epoch %d, want a verification error", tt.name, err, state.Epoch)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/ssh/terminal"
+	"io.librablock.go/keys"
+)
+
+func usage() {
+	fmt.Println("usage: wallet create | list")
+	os.Exit(2)
+}
+
+func readPassword(confirm bool) (string, error) {
+	if password := os.Getenv("LIBRA_KEYSTORE_PASSWORD"); password != "" {
+		return password, nil
+	}
+
+	password, err := promptPassword("Password: ")
+	if err != nil || !confirm {
+		return password, err
+	}
+
+	repeated, err := promptPassword("Repeat password: ")
+	if err != nil {
+		return "", err
+	}
+	if repeated != password {
+		return "", errors.New("passwords do not match")
+	}
+
+	return password, nil
+}
+
+// stdin is shared by both prompts when it is not a terminal
+var stdin = bufio.NewReader(os.Stdin)
+
+func promptPassword(prompt string) (string, error) {
+	fmt.Print(prompt)
+	fd := int(os.Stdin.Fd())
+	if terminal.IsTerminal(fd) {
+		password, err := terminal.ReadPassword(fd)
+		fmt.Println()
+		return string(password), err
+	}
+
+	line, err := stdin.ReadString('\n')
+	return strings.TrimRight(line, "\r\n"), err
+}
+
+func main() {
+	dir := os.Getenv("LIBRA_KEYSTORE_DIR")
+	if dir == "" {
+		dir = "keystore"
+	}
+
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	ks := keys.NewKeyStore(dir)
+
+	switch os.Args[1] {
+	case "create":
+		password, err := readPassword(true)
+		if err != nil {
+			fmt.Printf("Failed to read password: %s\n", err.Error())
+			os.Exit(1)
+		}
+
+		key, err := ks.Create(password)
+		if err != nil {
+			fmt.Printf("Failed to create wallet: %s\n", err.Error())
+			os.Exit(1)
+		}
+
+		fmt.Printf("Address: %s\n", key.AddressHex())
+		fmt.Printf("Public Key: %x\n", []byte(key.PublicKey))
+	case "list":
+		files, err := ks.List()
+		if err != nil {
+			fmt.Printf("Failed to list wallets: %s\n", err.Error())
+			os.Exit(1)
+		}
+
+		for i, file := range files {
+			fmt.Printf("#%d %s\n", i, file.Address)
+		}
+	default:
+		usage()
+	}
+}